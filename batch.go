@@ -0,0 +1,77 @@
+package tailreader
+
+import (
+	"errors"
+	"time"
+)
+
+// batchChunkSize is the maximum size of a chunk returned by ReadBatch
+const batchChunkSize = 32 * 1024
+
+// ReadBatch returns up to max chunks of data, reducing per-call overhead for batch consumers
+//
+// It blocks like Read until there is data, then keeps collecting the chunks
+// that arrive within maxWait. Errors (including io.EOF) that occur after the
+// first chunk end the batch early and are returned by the next call.
+func (r *TailingReader) ReadBatch(max int, maxWait time.Duration) ([][]byte, error) {
+	if r.batchErr != nil {
+		err := r.batchErr
+		r.batchErr = nil
+		return nil, err
+	}
+
+	buf := make([]byte, batchChunkSize)
+
+	n, err := r.Read(buf)
+	if n == 0 {
+		return nil, err
+	}
+
+	batch := [][]byte{append([]byte(nil), buf[:n]...)}
+
+	deadline := r.now().Add(maxWait)
+	for len(batch) < max {
+		n, _, err = r.read(buf, deadline)
+		if n > 0 {
+			batch = append(batch, append([]byte(nil), buf[:n]...))
+		}
+
+		if errors.Is(err, errTimeout) {
+			break
+		} else if err != nil {
+			r.batchErr = err
+			break
+		}
+	}
+
+	return batch, nil
+}
+
+// ReadRecordBatch returns up to max records, see TailingReader.ReadBatch
+func (rr *RecordReader) ReadRecordBatch(max int, maxWait time.Duration) ([]Record, error) {
+	if rr.batchErr != nil {
+		err := rr.batchErr
+		rr.batchErr = nil
+		return nil, err
+	}
+
+	rec, err := rr.ReadRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	batch := []Record{rec}
+	deadline := rr.tr.now().Add(maxWait)
+	for len(batch) < max {
+		rec, err = rr.readRecord(deadline)
+		if errors.Is(err, errTimeout) {
+			break
+		} else if err != nil {
+			rr.batchErr = err
+			break
+		}
+		batch = append(batch, rec)
+	}
+
+	return batch, nil
+}
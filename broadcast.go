@@ -0,0 +1,103 @@
+package tailreader
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcaster reads a file once and fans its chunks out to multiple subscribers
+//
+// Every subscriber has its own queue and overflow policy, so e.g. a UI stream
+// dropping chunks and an archiver that must see everything can follow the same
+// file without a second reader. Note that a subscriber using the Block policy
+// stops reading for everyone while its queue is full.
+type Broadcaster struct {
+	tr *TailingReader
+
+	mu   sync.Mutex
+	subs map[*ChunkStream]struct{}
+	done bool  // whether Run has returned
+	err  error // error Run returned
+}
+
+// NewBroadcaster returns a Broadcaster for tr; reading starts with Run
+func NewBroadcaster(tr *TailingReader) *Broadcaster {
+	return &Broadcaster{
+		tr:   tr,
+		subs: make(map[*ChunkStream]struct{}),
+	}
+}
+
+// Subscribe returns a stream receiving all chunks read from now on
+//
+// If the broadcaster has already finished, the returned stream is closed.
+func (b *Broadcaster) Subscribe(options ...ChunkOption) *ChunkStream {
+	s := newChunkStream(options)
+	s.unsubscribed = make(chan struct{})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		s.err = b.err
+		close(s.c)
+		return s
+	}
+
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe stops delivering chunks to s and closes its channel
+func (b *Broadcaster) Unsubscribe(s *ChunkStream) {
+	b.mu.Lock()
+	_, ok := b.subs[s]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	// unblock a pending send before waiting for the lock
+	s.unsubscribeOnce.Do(func() {
+		close(s.unsubscribed)
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// Run reads the file and delivers its chunks until reading ends or ctx is cancelled
+//
+// The reader is closed when ctx is cancelled. When Run returns, all
+// subscriptions are closed; their Err returns the error that ended reading.
+func (b *Broadcaster) Run(ctx context.Context) error {
+	err := b.tr.pumpChunks(ctx, func(chunk Chunk) bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for s := range b.subs {
+			if !s.send(ctx, chunk) {
+				return false
+			}
+		}
+		return true
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.done = true
+	b.err = err
+	for s := range b.subs {
+		s.err = err
+		close(s.c)
+	}
+	b.subs = nil
+
+	return err
+}
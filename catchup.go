@@ -0,0 +1,27 @@
+package tailreader
+
+// catchingUp checks whether the reader is still reading data that existed before it caught up
+func (r *TailingReader) catchingUp() bool {
+	select {
+	case <-r.caughtUp:
+		return false
+	default:
+		return true
+	}
+}
+
+// rateLimit returns the rate limit of the current phase, see CatchUpRateLimit
+func (r *TailingReader) rateLimit() int64 {
+	if r.options.CatchUpRateLimit > 0 && r.catchingUp() {
+		return r.options.CatchUpRateLimit
+	}
+	return r.options.RateLimit
+}
+
+// readaheadSize returns the readahead of the current phase, see CatchUpReadahead
+func (r *TailingReader) readaheadSize() int {
+	if r.options.CatchUpReadahead > 0 && r.catchingUp() {
+		return r.options.CatchUpReadahead
+	}
+	return r.options.Readahead
+}
@@ -0,0 +1,221 @@
+package tailreader
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpoint is the saved reading position of a file
+type Checkpoint struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+
+	// FileID identifies the file Offset refers to (device and inode on Unix), empty if unknown
+	// A checkpoint of a file that has since been replaced at Path (e.g. rotated) isn't resumed.
+	FileID string `json:"file_id,omitempty"`
+
+	// Checksum is the hex encoded running checksum of the data up to Offset (see WithChecksum)
+	Checksum string `json:"checksum,omitempty"`
+
+	// ChecksumState is the internal state of the checksum, used to continue it when resuming
+	ChecksumState []byte `json:"checksum_state,omitempty"`
+}
+
+// CheckpointStore persists checkpoints by file path
+//
+// Load returns an error wrapping fs.ErrNotExist if there's no checkpoint for the path.
+type CheckpointStore interface {
+	Load(path string) (Checkpoint, error)
+	Save(cp Checkpoint) error
+}
+
+var ErrNoCheckpointStore = fmt.Errorf("no checkpoint store")
+
+// FileCheckpointStore stores checkpoints of any number of files in a single JSON file
+//
+// The file is replaced atomically on every Save, so it's never left half written.
+type FileCheckpointStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+func (s *FileCheckpointStore) Load(path string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.load()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	cp, ok := checkpoints[path]
+	if !ok {
+		return Checkpoint{}, fmt.Errorf("checkpoint for %s: %w", path, fs.ErrNotExist)
+	}
+
+	return cp, nil
+}
+
+func (s *FileCheckpointStore) Save(cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.load()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if checkpoints == nil {
+		checkpoints = make(map[string]Checkpoint)
+	}
+	checkpoints[cp.Path] = cp
+
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileCheckpointStore) load() (map[string]Checkpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoints map[string]Checkpoint
+	err = json.Unmarshal(data, &checkpoints)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint file %s: %w", s.path, err)
+	}
+
+	return checkpoints, nil
+}
+
+// loadCheckpoint returns the checkpoint saved in the checkpoint store, if any
+func (r *TailingReader) loadCheckpoint() (Checkpoint, bool, error) {
+	if r.options.CheckpointStore == nil {
+		return Checkpoint{}, false, nil
+	}
+
+	cp, err := r.options.CheckpointStore.Load(r.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Checkpoint{}, false, nil
+	} else if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	return cp, true, nil
+}
+
+// restoreCheckpoint continues reading (and checksumming) where the checkpoint was saved
+//
+// If the checkpoint is of a file that was replaced since, the new file is read from the start.
+func (r *TailingReader) restoreCheckpoint(cp Checkpoint) error {
+	if id := r.fileID(); cp.FileID != "" && id != "" && id != cp.FileID {
+		cp = Checkpoint{}
+	}
+
+	if r.hash != nil && len(cp.ChecksumState) > 0 {
+		err := r.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(cp.ChecksumState)
+		if err != nil {
+			return fmt.Errorf("checkpoint checksum: %w", err)
+		}
+	}
+
+	if cp.Offset != r.offset-int64(len(r.pending)) {
+		_ = r.closeFile()
+		r.offset = cp.Offset
+	}
+	return nil
+}
+
+// SaveCheckpoint saves the current offset to the checkpoint store
+//
+// Call it once the data returned by Read has been processed, so a new reader
+// created with the same store resumes right after it.
+func (r *TailingReader) SaveCheckpoint() error {
+	if r.options.CheckpointStore == nil {
+		return ErrNoCheckpointStore
+	}
+
+	r.mu.Lock()
+	cp := Checkpoint{
+		Path:   r.filePath,
+		Offset: r.position(),
+		FileID: r.fileID(),
+	}
+	if r.hash != nil {
+		cp.Checksum = hex.EncodeToString(r.hash.Sum(nil))
+		cp.ChecksumState, _ = r.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	}
+	r.mu.Unlock()
+
+	return r.options.CheckpointStore.Save(cp)
+}
+
+// SaveCheckpointAt saves offset, e.g. the end of the data processed so far, to the checkpoint store
+//
+// The checksum covers the data read so far, so it's only saved if offset is the current position.
+func (r *TailingReader) SaveCheckpointAt(offset int64) error {
+	if r.options.CheckpointStore == nil {
+		return ErrNoCheckpointStore
+	}
+
+	r.mu.Lock()
+	cp := Checkpoint{
+		Path:   r.filePath,
+		Offset: offset,
+		FileID: r.fileID(),
+	}
+	if r.hash != nil && offset == r.position() {
+		cp.Checksum = hex.EncodeToString(r.hash.Sum(nil))
+		cp.ChecksumState, _ = r.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	}
+	r.mu.Unlock()
+
+	return r.options.CheckpointStore.Save(cp)
+}
+
+// fileID returns the identity of the file being read, or "" if it's unknown
+func (r *TailingReader) fileID() string {
+	var info fs.FileInfo
+	var err error
+	if r.osFile != nil {
+		info, err = r.osFile.Stat()
+	} else {
+		info, err = r.fs.Stat(r.filePath)
+	}
+	if err != nil {
+		return ""
+	}
+	return fileIDOf(info)
+}
@@ -0,0 +1,211 @@
+package tailreader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultQueueSize is the number of chunks a ChunkStream queues if none is set
+const DefaultQueueSize = 64
+
+// Chunk is a piece of data as returned by a single Read
+type Chunk struct {
+	Path   string    // path of the file the chunk was read from
+	Offset int64     // file offset of the chunk's first byte
+	Data   []byte    // the data
+	Time   time.Time // time the chunk was read
+}
+
+// OverflowPolicy decides what a ChunkStream does when its queue is full
+type OverflowPolicy int
+
+const (
+	// Block stops reading until the consumer catches up
+	Block OverflowPolicy = iota
+
+	// DropOldest discards the oldest queued chunk to make room for the new one
+	DropOldest
+
+	// DropNewest discards the new chunk
+	DropNewest
+)
+
+// ChunkOption configures a ChunkStream
+type ChunkOption func(s *ChunkStream)
+
+// ChunkStream delivers the chunks read by a TailingReader over a bounded channel
+//
+// The stream owns the reader: it is closed when the context passed to Chunks
+// is cancelled. Once the channel is closed, Err returns the reason.
+type ChunkStream struct {
+	c         chan Chunk
+	queueSize int
+	overflow  OverflowPolicy
+	dropped   atomic.Uint64
+	err       error
+
+	unsubscribed    chan struct{} // closed by Broadcaster.Unsubscribe
+	unsubscribeOnce sync.Once
+}
+
+// WithQueueSize sets the number of chunks queued for the consumer
+func WithQueueSize(size int) ChunkOption {
+	return func(s *ChunkStream) {
+		s.queueSize = size
+	}
+}
+
+// WithOverflowPolicy sets what happens when the queue is full
+func WithOverflowPolicy(policy OverflowPolicy) ChunkOption {
+	return func(s *ChunkStream) {
+		s.overflow = policy
+	}
+}
+
+// Chunks starts a goroutine delivering the data read from the file as chunks
+//
+// A slow consumer either stops reading (Block, the default) or loses chunks
+// (DropOldest, DropNewest), which are counted by Dropped; memory use is
+// bounded by the queue size in either case.
+func (r *TailingReader) Chunks(ctx context.Context, options ...ChunkOption) *ChunkStream {
+	s := newChunkStream(options)
+
+	go func() {
+		defer close(s.c)
+
+		s.err = r.pumpChunks(ctx, func(chunk Chunk) bool {
+			return s.send(ctx, chunk)
+		})
+	}()
+
+	return s
+}
+
+// Run passes the data read from the file to handler as chunks until reading ends
+//
+// It is meant to be run by an errgroup or a similar supervisor: Run owns the
+// reader and closes it before returning. It returns nil if reading ended with
+// io.EOF, ctx.Err() if ctx was cancelled, the handler's error if it failed,
+// and the error that ended reading otherwise.
+func (r *TailingReader) Run(ctx context.Context, handler func(Chunk) error) error {
+	var handlerErr error
+	err := r.pumpChunks(ctx, func(chunk Chunk) bool {
+		handlerErr = handler(chunk)
+		return handlerErr == nil
+	})
+	if handlerErr != nil {
+		err = handlerErr
+	}
+
+	closeErr := r.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func newChunkStream(options []ChunkOption) *ChunkStream {
+	s := &ChunkStream{
+		queueSize: DefaultQueueSize,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	s.c = make(chan Chunk, s.queueSize)
+	return s
+}
+
+// pumpChunks reads chunks and passes them to deliver until reading ends or deliver returns false
+//
+// The reader is closed when ctx is cancelled. The returned error is nil if
+// reading ended with io.EOF, and ctx.Err() if it was cancelled.
+func (r *TailingReader) pumpChunks(ctx context.Context, deliver func(Chunk) bool) error {
+	stop := context.AfterFunc(ctx, func() {
+		_ = r.Close()
+	})
+	defer stop()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, off, err := r.ReadAtOffset(buf)
+		if n > 0 {
+			chunk := Chunk{
+				Path:   r.FilePath(),
+				Offset: off,
+				Data:   append([]byte(nil), buf[:n]...),
+				Time:   r.now(),
+			}
+			if !deliver(chunk) {
+				return ctx.Err()
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, ErrClosed) && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// send queues a chunk according to the overflow policy; false if ctx is done
+func (s *ChunkStream) send(ctx context.Context, chunk Chunk) bool {
+	switch s.overflow {
+	case DropNewest:
+		select {
+		case s.c <- chunk:
+		default:
+			s.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.c <- chunk:
+				return true
+			default:
+			}
+
+			select {
+			case <-s.c:
+				s.dropped.Add(1)
+			default:
+				// the consumer made room meanwhile
+			}
+		}
+	default:
+		select {
+		case s.c <- chunk:
+		case <-s.unsubscribed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// C returns the channel chunks are delivered on; it's closed when reading ends
+func (s *ChunkStream) C() <-chan Chunk {
+	return s.c
+}
+
+// Dropped returns the number of chunks discarded because the queue was full
+func (s *ChunkStream) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Err returns the error that ended the stream, or nil if it ended with io.EOF
+//
+// Err must only be called after the channel has been closed.
+func (s *ChunkStream) Err() error {
+	return s.err
+}
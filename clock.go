@@ -0,0 +1,48 @@
+package tailreader
+
+import "time"
+
+// Clock is the source of time used for timeouts and polling
+//
+// It can be replaced via WithClock, e.g. to test timeouts deterministically.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock, see time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// realClock is the Clock backed by package time
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+func (r *TailingReader) clock() Clock {
+	if r.options.Clock != nil {
+		return r.options.Clock
+	}
+	return realClock{}
+}
+
+func (r *TailingReader) now() time.Time {
+	return r.clock().Now()
+}
@@ -0,0 +1,116 @@
+package tailreader
+
+import (
+	"fmt"
+	"os"
+)
+
+var errFileReplaced = fmt.Errorf("clone: file was replaced and cannot be reopened")
+
+// Clone returns an independent reader continuing at the current position of r
+//
+// The clone has the same options and starts at Offset(), so data read ahead
+// by r is delivered by the clone as well. If r has the file open, the clone
+// reads the same file even if it has been rotated away from its path
+// meanwhile (on Linux; elsewhere Clone fails in this case). The clone
+// neither uses the LockFile held by r nor its CheckpointStore.
+func (r *TailingReader) Clone() (*TailingReader, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return nil, ErrClosed
+	}
+
+	path := r.filePath
+	if len(r.paths) > 0 {
+		// the path passed to the constructor, which may be a pattern
+		path = r.paths[0]
+	}
+
+	options := *r.options
+	options.LockFile = ""
+	options.CheckpointStore = nil
+	clone, err := newTailingReader(path, []Option{func(opts *Options) {
+		*opts = options
+	}})
+	if err != nil {
+		return nil, err
+	}
+	clone.filePath = r.filePath
+	clone.paths = r.paths
+
+	if r.polling {
+		fsys := r.fs
+		if hooked, ok := fsys.(hookFS); ok {
+			fsys = hooked.FS
+		}
+		clone.startPolling(fsys)
+	} else {
+		err = clone.startWatching()
+		if err != nil {
+			_ = clone.Close()
+			return nil, err
+		}
+	}
+
+	clone.offset = r.position()
+	if r.file != nil {
+		err = clone.openSameFile(r.osFile)
+		if err != nil {
+			_ = clone.Close()
+			return nil, err
+		}
+	}
+
+	clone.startStats()
+	return clone, nil
+}
+
+// openSameFile opens the file that is open as orig (nil if it's not an OS file)
+func (r *TailingReader) openSameFile(orig *os.File) error {
+	offset := r.offset
+
+	err := r.openFile()
+	if err == nil && (orig == nil || sameFile(orig, r.osFile)) {
+		return nil
+	}
+	_ = r.closeFile()
+	r.offset = offset
+
+	if orig == nil {
+		return err
+	}
+
+	// the path refers to a different file (or none at all) now
+	file, err := reopenFile(orig, os.O_RDONLY|r.openFlags())
+	if err != nil {
+		return err
+	}
+
+	err = seekFile(file, offset)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.setFile(file)
+	return nil
+}
+
+func sameFile(a *os.File, b *os.File) bool {
+	if b == nil {
+		return false
+	}
+
+	infoA, err := a.Stat()
+	if err != nil {
+		return false
+	}
+	infoB, err := b.Stat()
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(infoA, infoB)
+}
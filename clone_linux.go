@@ -0,0 +1,24 @@
+package tailreader
+
+import (
+	"fmt"
+	"os"
+)
+
+// reopenFile opens the file referred to by an open file, which may no longer be reachable by its name
+func reopenFile(file *os.File, flag int) (*os.File, error) {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var path string
+	err = conn.Control(func(fd uintptr) {
+		path = fmt.Sprintf("/proc/self/fd/%d", fd)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return os.OpenFile(path, flag, 0)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package tailreader
+
+import "os"
+
+// reopenFile opens the file referred to by an open file, which may no longer be reachable by its name
+func reopenFile(file *os.File, flag int) (*os.File, error) {
+	return nil, errFileReplaced
+}
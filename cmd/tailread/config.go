@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// configPollInterval is how often the config file is checked for changes
+var configPollInterval = time.Second
+
+// loadConfig reads the config file and returns the options of every file by its path
+//
+// The format is that of tailreader.ManagerConfig.
+func loadConfig(path string) (map[string][]tailreader.Option, error) {
+	cfg, err := tailreader.LoadManagerConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := cfg.FileOptions()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return files, nil
+}
+
+// configWatcher tails the files listed in the config file and applies changes to it
+type configWatcher struct {
+	path   string
+	common []tailreader.Option // options for all files, e.g. the checkpoint store
+	tailer *tailer
+
+	readers map[string]*tailreader.TailingReader // readers of the listed files by path
+	modTime time.Time
+	size    int64
+}
+
+// reload reads the config file and starts, updates or stops tailing the listed files
+//
+// On error, the files are tailed as before.
+func (w *configWatcher) reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	files, err := loadConfig(w.path)
+	if err != nil {
+		return err
+	}
+
+	if w.readers == nil {
+		w.readers = make(map[string]*tailreader.TailingReader)
+	}
+
+	for path, tr := range w.readers {
+		if _, ok := files[path]; !ok {
+			w.tailer.remove(tr)
+			delete(w.readers, path)
+		}
+	}
+
+	var errs []error
+	for path, options := range files {
+		options = append(options, w.common...)
+
+		if tr, ok := w.readers[path]; ok {
+			err = tr.UpdateOptions(options...)
+		} else {
+			// retried by the next reload if it fails
+			tr, err = w.tailer.add(path, options)
+			if err == nil {
+				w.readers[path] = tr
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// watch reloads the config file whenever it changes until done is closed; errors are written to errOut
+func (w *configWatcher) watch(done <-chan struct{}, errOut io.Writer) {
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+
+		info, err := os.Stat(w.path)
+		if err != nil || info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+			continue
+		}
+
+		err = w.reload()
+		if err != nil {
+			fmt.Fprintf(errOut, "tailread: reload: %v\n", err)
+		}
+	}
+}
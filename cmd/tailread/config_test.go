@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tailread.yaml")
+	err := os.WriteFile(path, []byte(`
+options:
+  idle_timeout: 1m
+  start_at_end: true
+files:
+  - path: /var/log/a.log
+  - path: /var/log/b.log
+    options:
+      start_at_end: false
+`), 0644)
+	assert.NoError(t, err)
+
+	files, err := loadConfig(path)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	var a, b tailreader.Options
+	for _, option := range files["/var/log/a.log"] {
+		option(&a)
+	}
+	for _, option := range files["/var/log/b.log"] {
+		option(&b)
+	}
+
+	assert.Equal(t, time.Minute, a.IdleTimeout)
+	assert.True(t, a.StartAtEnd)
+	assert.Equal(t, time.Minute, b.IdleTimeout)
+	assert.False(t, b.StartAtEnd)
+
+	err = os.WriteFile(path, []byte("files:\n  - path: a.log\n    options:\n      idle_timeuot: 1m\n"), 0644)
+	assert.NoError(t, err)
+	_, err = loadConfig(path)
+	assert.ErrorContains(t, err, "idle_timeuot")
+}
+
+func TestRunWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPollInterval = 10 * time.Millisecond
+
+	a := filepath.Join(dir, "a.log")
+	b := filepath.Join(dir, "b.log")
+	assert.NoError(t, os.WriteFile(a, []byte("Hello, A!\n"), 0644))
+	assert.NoError(t, os.WriteFile(b, []byte("Hello, B!\n"), 0644))
+
+	config := filepath.Join(dir, "tailread.yaml")
+	header := "options:\n  idle_timeout: 300ms\n  timeouts_as_eof: true\nfiles:\n"
+	assert.NoError(t, os.WriteFile(config, []byte(header+"  - path: "+a+"\n"), 0644))
+
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- run([]string{"--config", config}, &out)
+	}()
+
+	// files added to the config are tailed without restarting
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(config, []byte(header+"  - path: "+a+"\n  - path: "+b+"\n"), 0644))
+
+	assert.NoError(t, <-done)
+	assert.Contains(t, out.String(), "==> "+a+" <==\nHello, A!\n")
+	assert.Contains(t, out.String(), "==> "+b+" <==\nHello, B!\n")
+}
+
+func TestConfigWatcher_ReloadAfterFailedAdd(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.log")
+	assert.NoError(t, os.WriteFile(a, []byte("Hello, A!\n"), 0644))
+
+	config := filepath.Join(dir, "tailread.yaml")
+	assert.NoError(t, os.WriteFile(config, []byte("files:\n  - path: "+a+"\n"), 0644))
+
+	// the common options are invalid, so adding the file fails
+	cw := &configWatcher{path: config, common: []tailreader.Option{tailreader.WithIdleTimeout(-1)}, tailer: newTailer(0, nil, false)}
+	assert.ErrorIs(t, cw.reload(), tailreader.ErrInvalidOptions)
+	assert.Empty(t, cw.readers)
+
+	assert.ErrorIs(t, cw.reload(), tailreader.ErrInvalidOptions)
+	assert.Empty(t, cw.readers)
+}
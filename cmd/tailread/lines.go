@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// lineFilter selects the lines to output, see --grep and --grep-v
+type lineFilter struct {
+	match   *regexp.Regexp // lines must match this, if set
+	exclude *regexp.Regexp // lines must not match this, if set
+}
+
+// newLineFilter compiles the expressions; it returns nil if both are empty
+func newLineFilter(match string, exclude string) (*lineFilter, error) {
+	if match == "" && exclude == "" {
+		return nil, nil
+	}
+
+	f := &lineFilter{}
+	var err error
+	if match != "" {
+		f.match, err = regexp.Compile(match)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if exclude != "" {
+		f.exclude, err = regexp.Compile(exclude)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// selects reports whether line (without its line break) is output
+func (f *lineFilter) selects(line []byte) bool {
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}), []byte{'\r'})
+	if f.match != nil && !f.match.Match(line) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude.Match(line)
+}
+
+// lineSplitter splits the chunks read from a file into chunks of complete lines
+type lineSplitter struct {
+	partial chunk // incomplete last line
+}
+
+// split returns the complete lines of c (including the incomplete line of the previous chunk)
+func (s *lineSplitter) split(c chunk) []chunk {
+	if len(s.partial.data) > 0 {
+		c.offset = s.partial.offset
+		c.data = append(s.partial.data, c.data...)
+		s.partial = chunk{}
+	}
+
+	var lines []chunk
+	for len(c.data) > 0 {
+		i := bytes.IndexByte(c.data, '\n')
+		if i < 0 {
+			s.partial = c
+			break
+		}
+
+		line := c
+		line.data = c.data[:i+1]
+		lines = append(lines, line)
+
+		c.offset += int64(i + 1)
+		c.data = c.data[i+1:]
+	}
+	return lines
+}
+
+// flush returns the incomplete last line, if any
+func (s *lineSplitter) flush() (chunk, bool) {
+	partial := s.partial
+	s.partial = chunk{}
+	return partial, len(partial.data) > 0
+}
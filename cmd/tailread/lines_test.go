@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineFilter(t *testing.T) {
+	f, err := newLineFilter("error|warn", "ignored")
+	assert.NoError(t, err)
+
+	assert.True(t, f.selects([]byte("an error\n")))
+	assert.True(t, f.selects([]byte("a warning\r\n")))
+	assert.False(t, f.selects([]byte("info\n")))
+	assert.False(t, f.selects([]byte("an ignored error\n")))
+
+	f, err = newLineFilter("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+
+	_, err = newLineFilter("(", "")
+	assert.Error(t, err)
+}
+
+func TestLineSplitter(t *testing.T) {
+	var s lineSplitter
+
+	lines := s.split(chunk{path: "a", offset: 0, data: []byte("one\ntw")})
+	assert.Equal(t, []chunk{{path: "a", offset: 0, data: []byte("one\n")}}, lines)
+
+	lines = s.split(chunk{path: "a", offset: 6, data: []byte("o\nthree\nfo")})
+	assert.Equal(t, []chunk{
+		{path: "a", offset: 4, data: []byte("two\n")},
+		{path: "a", offset: 8, data: []byte("three\n")},
+	}, lines)
+
+	partial, ok := s.flush()
+	assert.True(t, ok)
+	assert.Equal(t, chunk{path: "a", offset: 14, data: []byte("fo")}, partial)
+
+	_, ok = s.flush()
+	assert.False(t, ok)
+}
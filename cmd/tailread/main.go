@@ -0,0 +1,564 @@
+// Command tailread tails files and writes their content to stdout.
+//
+// Usage:
+//
+//	tailread [flags] [--config <file>] <file|glob>...
+//
+// It exposes the options of the tailreader package as flags and exits with
+// status 0 once all readers signal io.EOF (e.g. on delete with --close-on-delete
+// or on timeouts with --timeouts-as-eof), and with status 1 on any error.
+//
+// If more than one file is tailed (or a config file is given), output is preceded
+// by a "==> path <==" header whenever it switches to a different file, like GNU
+// tail does.
+//
+// The output format is selected by --format:
+//
+//	raw    the data as it is read (default)
+//	hex    a hex dump with file offsets
+//	jsonl  one JSON object per chunk with path, offset, time and data
+//
+// With --max-age, matching files not modified within the given duration are
+// skipped, and with --min-age, reading a file is delayed until it hasn't been
+// modified for the given duration, so partially written files aren't picked up.
+//
+// With --checkpoint, the offset of every file is saved once its data has been
+// written (at most every second, and on exit), and a restarted tailread
+// resumes right after it.
+//
+// With --config, the files listed in a YAML file are tailed (in addition to the
+// ones given as arguments), each with its own options. Changes to the file are
+// applied without restarting:
+//
+//	options:           # defaults for all files, named like the variables of tailreader.ConfigFromEnv
+//	  idle_timeout: 1m
+//	files:
+//	  - path: /var/log/app.log
+//	    options:
+//	      start_at_end: true
+//
+// Flags other than --checkpoint, --format and --min-age don't apply to these files.
+//
+// With --grep and --grep-v, only lines matching (or not matching) a regular
+// expression are output. Each line is output as a chunk of its own then; an
+// incomplete last line is held back until it's completed.
+//
+// With --timestamps and --prefix, every line is preceded by the time it was read
+// and the path of its file, e.g. to merge the output of multiple tails into one
+// stream. Like with --grep, an incomplete last line is held back until it's
+// completed.
+//
+// With --output, the output is written to a file as well, which is rotated by
+// size (--output-max-size) and/or age (--output-max-age) like logrotate does,
+// keeping --output-keep numbered copies (file.1 being the most recent one).
+//
+// On Unix, SIGHUP reopens files that were replaced (e.g. by logrotate)
+// and SIGUSR1 writes the offset and number of bytes read of every file to stderr.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// chunk is data read from one of the tailed files
+type chunk struct {
+	path   string
+	offset int64
+	time   time.Time
+	data   []byte
+	tr     *tailreader.TailingReader // reader the data was read with
+}
+
+// checkpointInterval is the interval at which the offsets of the written data are saved
+const checkpointInterval = time.Second
+
+// jsonChunk is the jsonl representation of a chunk
+type jsonChunk struct {
+	Path   string    `json:"path"`
+	Offset int64     `json:"offset"`
+	Time   time.Time `json:"time"`
+	Data   string    `json:"data"`
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tailread: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("tailread", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: tailread [flags] [--config <file>] <file|glob>...\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	wait := flags.Bool("wait", true, "wait for the file to be created")
+	waitTimeout := flags.Duration("wait-timeout", 0, "how long to wait for the file to be created (0 = forever)")
+	idleTimeout := flags.Duration("idle-timeout", 0, "how long to wait for new data (0 = forever)")
+	closeOnDelete := flags.Bool("close-on-delete", false, "stop when the file is deleted (the file has to exist unless --wait-timeout is set)")
+	closeOnTruncate := flags.Bool("close-on-truncate", false, "stop when the file is truncated")
+	timeoutsAsEOF := flags.Bool("timeouts-as-eof", false, "exit successfully on wait and idle timeouts")
+	startAtEnd := flags.Bool("start-at-end", false, "only output data appended after startup")
+	follow := flags.String("follow", "name", "after a file was renamed or deleted, follow its name or descriptor")
+	format := flags.String("format", "raw", "output format: raw, hex or jsonl")
+	checkpoint := flags.String("checkpoint", "", "file to save offsets to and resume from")
+	maxAge := flags.Duration("max-age", 0, "skip files not modified within this duration (0 = no limit)")
+	minAge := flags.Duration("min-age", 0, "delay files until they haven't been modified for this duration (0 = no delay)")
+	configPath := flags.String("config", "", "YAML file listing files to tail and their options, reloaded on change")
+	timestamps := flags.Bool("timestamps", false, "prefix every line with the time it was read")
+	prefix := flags.Bool("prefix", false, "prefix every line with the path of its file instead of printing headers")
+	grep := flags.String("grep", "", "only output lines matching this regular expression")
+	grepV := flags.String("grep-v", "", "don't output lines matching this regular expression")
+	output := flags.String("output", "", "file to write the output to as well")
+	outputMaxSize := flags.Int64("output-max-size", 0, "rotate the output file once it would exceed this many bytes (0 = no limit)")
+	outputMaxAge := flags.Duration("output-max-age", 0, "rotate the output file once it is older than this duration (0 = no limit)")
+	outputKeep := flags.Int("output-keep", 5, "number of rotated output files to keep")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 && *configPath == "" {
+		flags.Usage()
+		return fmt.Errorf("no files given")
+	}
+
+	var write func(out io.Writer, c chunk) error
+	switch *format {
+	case "raw":
+		write = writeRaw
+		if *timestamps || *prefix {
+			write = decorate(*timestamps, *prefix)
+		}
+	case "hex":
+		write = writeHex
+	case "jsonl":
+		write = writeJSONL
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *format != "raw" && (*timestamps || *prefix) {
+		return fmt.Errorf("--timestamps and --prefix only apply to the raw format")
+	}
+
+	if *outputKeep < 0 {
+		return fmt.Errorf("negative number of output files to keep")
+	}
+	if *output != "" {
+		f, err := openRotatingFile(*output, *outputMaxSize, *outputMaxAge, *outputKeep)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = io.MultiWriter(out, f)
+	}
+
+	paths, err := expandPaths(flags.Args(), *maxAge)
+	if err != nil {
+		return err
+	}
+
+	waitForFile := *wait
+	if *closeOnDelete && *waitTimeout == 0 && !isSet(flags, "wait") {
+		// a file deleted before it was opened would be waited for forever
+		waitForFile = false
+	}
+
+	options := []tailreader.Option{
+		tailreader.WithWaitForFile(waitForFile, *waitTimeout),
+		tailreader.WithIdleTimeout(*idleTimeout),
+		tailreader.WithCloseOnDelete(*closeOnDelete),
+		tailreader.WithCloseOnTruncate(*closeOnTruncate),
+		tailreader.WithTimeoutsAsEOF(*timeoutsAsEOF),
+		tailreader.WithStartAtEnd(*startAtEnd),
+		tailreader.WithFollowMode(tailreader.FollowMode(*follow)),
+	}
+
+	// options applying to the files of the config file as well
+	var common []tailreader.Option
+
+	var store *tailreader.FileCheckpointStore
+	if *checkpoint != "" {
+		store = tailreader.NewFileCheckpointStore(*checkpoint)
+		common = append(common, tailreader.WithCheckpointStore(store))
+	}
+	options = append(options, common...)
+
+	filter, err := newLineFilter(*grep, *grepV)
+	if err != nil {
+		return err
+	}
+
+	t := newTailer(*minAge, filter, *timestamps || *prefix)
+	defer t.closeAll()
+
+	for _, path := range paths {
+		_, err = t.add(path, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *configPath != "" {
+		cw := &configWatcher{path: *configPath, common: common, tailer: t}
+		err = cw.reload()
+		if err != nil {
+			return err
+		}
+
+		done := make(chan struct{})
+		defer close(done)
+		go cw.watch(done, os.Stderr)
+	}
+
+	stop := watchSignals(t.list, os.Stderr)
+	defer stop()
+
+	// the files listed in the config file may change
+	headers := (len(paths) > 1 || *configPath != "") && !*prefix
+
+	// offsets of the written data not saved yet, see checkpointInterval
+	checkpoints := make(map[*tailreader.TailingReader]int64)
+	saveCheckpoints := func() error {
+		for tr, offset := range checkpoints {
+			err := tr.SaveCheckpointAt(offset)
+			if err != nil {
+				return err
+			}
+			delete(checkpoints, tr)
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	current := ""
+	for {
+		var c chunk
+		var ok bool
+		select {
+		case c, ok = <-t.chunks:
+		case <-ticker.C:
+			err = saveCheckpoints()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			break
+		}
+
+		if headers && *format != "jsonl" && c.path != current {
+			if current != "" {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "==> %s <==\n", c.path)
+			current = c.path
+		}
+
+		err = write(out, c)
+		if err != nil {
+			return err
+		}
+
+		if store != nil {
+			checkpoints[c.tr] = c.offset + int64(len(c.data))
+		}
+	}
+
+	err = saveCheckpoints()
+	if err != nil {
+		return err
+	}
+	return t.err()
+}
+
+// tailer pumps the data of every tailed file into a single channel
+//
+// Files can be added and removed while tailing; chunks is closed once the
+// readers of all files have ended.
+type tailer struct {
+	chunks chan chunk
+	minAge time.Duration
+	filter *lineFilter // nil if all data is output
+	lines  bool        // whether chunks are split into lines (always the case with a filter)
+
+	mu      sync.Mutex
+	readers []*tailreader.TailingReader
+	active  int // number of readers that haven't ended yet
+	ended   bool
+	errs    []error
+}
+
+func newTailer(minAge time.Duration, filter *lineFilter, lines bool) *tailer {
+	return &tailer{
+		chunks: make(chan chunk),
+		minAge: minAge,
+		filter: filter,
+		lines:  lines || filter != nil,
+	}
+}
+
+// add starts tailing path and returns its reader
+func (t *tailer) add(path string, options []tailreader.Option) (*tailreader.TailingReader, error) {
+	tr, err := tailreader.NewTailingReader(path, options...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.ended {
+		_ = tr.Close()
+		return nil, fmt.Errorf("%s: no longer tailing", path)
+	}
+
+	t.readers = append(t.readers, tr)
+	t.active++
+
+	go func() {
+		err := t.pump(path, tr)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.errs = append(t.errs, err)
+		t.active--
+		if t.active == 0 {
+			t.ended = true
+			close(t.chunks)
+		}
+	}()
+
+	return tr, nil
+}
+
+// remove stops tailing the file read by tr
+func (t *tailer) remove(tr *tailreader.TailingReader) {
+	t.mu.Lock()
+	t.readers = slices.DeleteFunc(t.readers, func(r *tailreader.TailingReader) bool {
+		return r == tr
+	})
+	t.mu.Unlock()
+
+	// ends its pump without an error
+	_ = tr.Close()
+}
+
+// list returns the readers of the tailed files
+func (t *tailer) list() []*tailreader.TailingReader {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.readers)
+}
+
+func (t *tailer) closeAll() {
+	for _, tr := range t.list() {
+		_ = tr.Close()
+	}
+}
+
+// err returns the errors that ended the readers
+func (t *tailer) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return errors.Join(t.errs...)
+}
+
+// expandPaths expands glob patterns; patterns without matches are kept as
+// they are, so the reader can wait for the file to be created
+//
+// Existing files not modified within maxAge are skipped (unless it's 0).
+func expandPaths(args []string, maxAge time.Duration) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			paths = append(paths, arg)
+			continue
+		}
+
+		for _, match := range matches {
+			if maxAge > 0 && fileAge(match) > maxAge {
+				continue
+			}
+			paths = append(paths, match)
+		}
+	}
+	return paths, nil
+}
+
+// isSet checks whether the flag was given on the command line
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// fileAge returns the time since the file was last modified, or 0 if it doesn't exist
+func fileAge(path string) time.Duration {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return time.Since(info.ModTime())
+}
+
+// pump sends everything read from tr (or the lines selected by the filter) to chunks until the reader ends
+//
+// If minAge is set, reading starts once the file hasn't been modified for minAge.
+func (t *tailer) pump(path string, tr *tailreader.TailingReader) error {
+	for age := fileAge(path); t.minAge > 0 && age > 0 && age < t.minAge; age = fileAge(path) {
+		time.Sleep(t.minAge - age)
+	}
+
+	var lines lineSplitter
+	send := func(c chunk) {
+		if !t.lines {
+			t.chunks <- c
+			return
+		}
+
+		for _, line := range lines.split(c) {
+			if t.filter == nil || t.filter.selects(line.data) {
+				t.chunks <- line
+			}
+		}
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, off, err := tr.ReadAtOffset(buf)
+		if n > 0 {
+			send(chunk{
+				path:   path,
+				offset: off,
+				time:   time.Now(),
+				data:   append([]byte(nil), buf[:n]...),
+				tr:     tr,
+			})
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if line, ok := lines.flush(); ok && (t.filter == nil || t.filter.selects(line.data)) {
+			t.chunks <- line
+		}
+
+		if err == io.EOF || errors.Is(err, tailreader.ErrClosed) {
+			// closed when the file was removed from the config file
+			return nil
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+}
+
+// writeStats writes the stats of a reader as a single line
+func writeStats(w io.Writer, stats tailreader.Stats) {
+	fmt.Fprintf(w, "tailread: %s: offset=%d delivered=%d", stats.Path, stats.Offset, stats.Delivered)
+	if stats.Checksum != "" {
+		fmt.Fprintf(w, " checksum=%s", stats.Checksum)
+	}
+	fmt.Fprintln(w)
+}
+
+func writeRaw(out io.Writer, c chunk) error {
+	_, err := out.Write(c.data)
+	return err
+}
+
+// timestampFormat is the format of the times written by --timestamps
+const timestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// decorate returns a writer of raw data preceded by the time it was read and/or the path of its file
+//
+// The chunks are expected to be single lines.
+func decorate(timestamps bool, prefix bool) func(out io.Writer, c chunk) error {
+	return func(out io.Writer, c chunk) error {
+		var sb strings.Builder
+		if timestamps {
+			sb.WriteString(c.time.Format(timestampFormat))
+			sb.WriteByte(' ')
+		}
+		if prefix {
+			sb.WriteString(c.path)
+			sb.WriteString(": ")
+		}
+		sb.Write(c.data)
+
+		_, err := io.WriteString(out, sb.String())
+		return err
+	}
+}
+
+func writeJSONL(out io.Writer, c chunk) error {
+	return json.NewEncoder(out).Encode(jsonChunk{
+		Path:   c.path,
+		Offset: c.offset,
+		Time:   c.time,
+		Data:   string(c.data),
+	})
+}
+
+// writeHex writes a hex dump of the chunk in the format of hex.Dump, but with file offsets
+func writeHex(out io.Writer, c chunk) error {
+	var sb strings.Builder
+	for i := 0; i < len(c.data); i += 16 {
+		line := c.data[i:min(i+16, len(c.data))]
+
+		fmt.Fprintf(&sb, "%08x ", c.offset+int64(i))
+		for j := 0; j < 16; j++ {
+			if j == 8 {
+				sb.WriteByte(' ')
+			}
+			if j < len(line) {
+				fmt.Fprintf(&sb, " %02x", line[j])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+
+		sb.WriteString("  |")
+		for _, b := range line {
+			if b < 32 || b > 126 {
+				b = '.'
+			}
+			sb.WriteByte(b)
+		}
+		sb.WriteString("|\n")
+	}
+
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, str, out.String())
+}
+
+func TestRunWithCloseOnDelete(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- run([]string{"--close-on-delete", file.Name()}, &out)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, os.Remove(file.Name()))
+
+	select {
+	case err = <-done:
+		assert.NoError(t, err)
+		assert.Equal(t, str, out.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("tailing didn't stop after the file was deleted")
+	}
+
+	// an explicit --wait still requires a timeout
+	err = run([]string{"--close-on-delete", "--wait", file.Name()}, &out)
+	assert.ErrorContains(t, err, "invalid options")
+}
+
+func TestRunWithStartAtEnd(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--start-at-end", file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "", out.String())
+}
+
+func TestRunWithMultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "a.log"), []byte("Hello, A!\n"), 0o644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "b.log"), []byte("Hello, B!\n"), 0o644)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", filepath.Join(dir, "*.log")}, &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "==> "+filepath.Join(dir, "a.log")+" <==\nHello, A!\n")
+	assert.Contains(t, out.String(), "==> "+filepath.Join(dir, "b.log")+" <==\nHello, B!\n")
+}
+
+func TestRunWithFormat(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--format", "hex", file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "00000000  48 65 6c 6c 6f 2c 20 57  6f 72 6c 64 21           |Hello, World!|\n", out.String())
+
+	out.Reset()
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--format", "jsonl", file.Name()}, &out)
+	assert.NoError(t, err)
+
+	var c jsonChunk
+	err = json.Unmarshal(out.Bytes(), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, file.Name(), c.Path)
+	assert.Equal(t, int64(0), c.Offset)
+	assert.Equal(t, str, c.Data)
+}
+
+func TestRunWithCheckpoint(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--checkpoint", checkpoint, file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", out.String())
+
+	_, err = file.WriteString(" Hello again!")
+	assert.NoError(t, err)
+
+	out.Reset()
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--checkpoint", checkpoint, file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, " Hello again!", out.String())
+}
+
+func TestRunWithAgeFilter(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "old.log"), []byte("Hello, old!\n"), 0o644)
+	assert.NoError(t, err)
+	old := time.Now().Add(-time.Hour)
+	err = os.Chtimes(filepath.Join(dir, "old.log"), old, old)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "new.log"), []byte("Hello, new!\n"), 0o644)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	start := time.Now()
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--max-age", "1m", "--min-age", "200ms", filepath.Join(dir, "*.log")}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, new!\n", out.String())
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestRunWithOutput(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	output := filepath.Join(t.TempDir(), "out.log")
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--output", output, file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, str, out.String())
+
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(data))
+}
+
+func TestRunWithGrep(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("info: starting\nerror: failed\nwarn: retrying\nerror: ignored\ninfo: done")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--grep", "^(error|warn)", "--grep-v", "ignored", file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "error: failed\nwarn: retrying\n", out.String())
+}
+
+func TestRunWithPrefix(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("first\nsecond\nincomplete")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--prefix", file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, file.Name()+": first\n"+file.Name()+": second\n"+file.Name()+": incomplete", out.String())
+
+	out.Reset()
+	err = run([]string{"--idle-timeout", "100ms", "--timeouts-as-eof", "--timestamps", file.Name()}, &out)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}\S* (first\n|second\n|incomplete)){3}$`, out.String())
+
+	err = run([]string{"--format", "hex", "--prefix", file.Name()}, &out)
+	assert.Error(t, err)
+}
+
+func TestDecorate(t *testing.T) {
+	c := chunk{path: "app.log", time: time.Date(2024, 5, 1, 12, 30, 0, 250e6, time.UTC), data: []byte("hello\n")}
+
+	var out bytes.Buffer
+	assert.NoError(t, decorate(true, true)(&out, c))
+	assert.Equal(t, "2024-05-01T12:30:00.250Z app.log: hello\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, decorate(false, true)(&out, c))
+	assert.Equal(t, "app.log: hello\n", out.String())
+}
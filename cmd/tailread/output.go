@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// rotatingFile appends to a file that is rotated once it exceeds maxSize bytes or gets older than maxAge
+//
+// Like logrotate does, rotated copies are numbered: path.1 is the most recent
+// one, and only the keep most recent ones are retained. Limits of 0 disable
+// rotation by size or age.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	keep    int
+
+	file    *os.File
+	size    int64
+	created time.Time
+}
+
+func openRotatingFile(path string, maxSize int64, maxAge time.Duration, keep int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, maxAge: maxAge, keep: keep}
+	err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.created = time.Now()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.size > 0 && (f.maxSize > 0 && f.size+int64(len(p)) > f.maxSize || f.maxAge > 0 && time.Since(f.created) >= f.maxAge) {
+		err := f.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the file to path.1 (shifting the existing copies) and starts a new one
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(f.rotatedPath(f.keep))
+	for i := f.keep - 1; i >= 1; i-- {
+		err = os.Rename(f.rotatedPath(i), f.rotatedPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if f.keep > 0 {
+		err = os.Rename(f.path, f.rotatedPath(1))
+	} else {
+		err = os.Remove(f.path)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.open()
+}
+
+func (f *rotatingFile) rotatedPath(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+func (f *rotatingFile) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFileBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+
+	f, err := openRotatingFile(path, 10, 0, 2)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	for _, s := range []string{"0123456789", "abc", "defghij", "x"} {
+		_, err = f.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+
+	assertFile(t, path, "x")
+	assertFile(t, path+".1", "abcdefghij")
+	assertFile(t, path+".2", "0123456789")
+
+	// only two copies are kept
+	_, err = f.Write([]byte("0123456789"))
+	assert.NoError(t, err)
+
+	assertFile(t, path, "0123456789")
+	assertFile(t, path+".1", "x")
+	assertFile(t, path+".2", "abcdefghij")
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFileByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	assert.NoError(t, os.WriteFile(path, []byte("existing\n"), 0644))
+
+	f, err := openRotatingFile(path, 0, 50*time.Millisecond, 1)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("one\n"))
+	assert.NoError(t, err)
+	assertFile(t, path, "existing\none\n")
+
+	time.Sleep(50 * time.Millisecond)
+	_, err = f.Write([]byte("two\n"))
+	assert.NoError(t, err)
+
+	assertFile(t, path, "two\n")
+	assertFile(t, path+".1", "existing\none\n")
+}
+
+func assertFile(t *testing.T, path string, expected string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import (
+	"io"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// watchSignals does nothing; SIGHUP and SIGUSR1 only exist on Unix
+func watchSignals(readers func() []*tailreader.TailingReader, w io.Writer) func() {
+	return func() {}
+}
@@ -0,0 +1,50 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// watchSignals handles signals for the current readers until the returned function is called
+//
+// On SIGHUP, files whose path refers to a new file (e.g. after log rotation)
+// are reopened and read from the beginning, which is the contract of logrotate's
+// postrotate scripts. On SIGUSR1, the stats of all readers are written to w.
+func watchSignals(readers func() []*tailreader.TailingReader, w io.Writer) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGUSR1)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				for _, tr := range readers() {
+					if sig == syscall.SIGUSR1 {
+						writeStats(w, tr.Stats())
+						continue
+					}
+
+					_, err := tr.ReopenIfReplaced()
+					if err != nil {
+						fmt.Fprintf(w, "tailread: %s: reopen: %v\n", tr.FilePath(), err)
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchSignals(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+	defer os.Remove(file.Name() + ".1")
+
+	_, err := file.WriteString("one\n")
+	assert.NoError(t, err)
+
+	tr, _ := tailreader.NewTailingReader(file.Name(), tailreader.WithFollowMode(tailreader.FollowDescriptor))
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "one\n", string(buf[:n]))
+
+	pr, pw := io.Pipe()
+	stop := watchSignals(func() []*tailreader.TailingReader {
+		return []*tailreader.TailingReader{tr}
+	}, pw)
+	defer stop()
+
+	// the reader follows the rotated file until it's told to reopen the path
+	assert.NoError(t, os.Rename(file.Name(), file.Name()+".1"))
+	assert.NoError(t, os.WriteFile(file.Name(), []byte("second\n"), 0644))
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "second\n", string(buf[:n]))
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	line, err := bufio.NewReader(pr).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "tailread: "+file.Name()+": offset=7 delivered=11\n", line)
+}
@@ -0,0 +1,257 @@
+package tailreader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is a plain struct alternative to the functional options
+//
+// See Options for the meaning of the fields.
+type Config struct {
+	WaitForFile        bool
+	WaitForFileTimeout time.Duration
+	WaitForRegularFile bool
+	CloseOnDelete      bool
+	DetectUnlink       bool
+	CloseOnTruncate    bool
+	SeekOnTruncate     bool
+	IdleTimeout        time.Duration
+	TreatTimeoutsAsEOF bool
+	MmapThreshold      int64
+	Fadvise            bool
+	NoWaitRead         bool
+	DirectIO           bool
+	SkipHoles          bool
+	Readahead          int
+	Prefetch           bool
+	BufferSize         int
+	SpillDir           string
+	MaxSpillSize       int64
+	StabilityWindow    time.Duration
+	GrowthLimit        int64
+	GrowthLimitPeriod  time.Duration
+	RateLimit          int64
+	CatchUpRateLimit   int64
+	CatchUpReadahead   int
+	Checksum           Checksum
+	DetectModification bool
+	IgnoreChmod        bool
+	FollowMode         FollowMode
+	FallbackPaths      []string
+	Glob               bool
+	CaseInsensitive    bool
+	ResolveSymlinks    bool
+	WatchFile          bool
+	WatchBufferSize    int
+	NFS                bool
+	PollInterval       time.Duration
+	StartAtEnd         bool
+	LockFile           string
+	RespectWriterLock  bool
+}
+
+// DefaultConfig returns the configuration equivalent to DefaultOptions
+func DefaultConfig() Config {
+	return Config{
+		WaitForFile: true,
+	}
+}
+
+// ConfigFromEnv returns DefaultConfig overridden by environment variables
+//
+// The variables are named after the fields with the given prefix, e.g. with
+// prefix "TAILREADER_": TAILREADER_WAIT_FOR_FILE=true, TAILREADER_IDLE_TIMEOUT=1m.
+// Durations are parsed by time.ParseDuration, booleans by strconv.ParseBool.
+// Lists of paths are separated by os.PathListSeparator (":" on Unix).
+func ConfigFromEnv(prefix string) (Config, error) {
+	cfg := DefaultConfig()
+
+	for _, v := range cfg.vars() {
+		value, ok := os.LookupEnv(prefix + v.name)
+		if !ok {
+			continue
+		}
+
+		err := v.parse(value)
+		if err != nil {
+			return cfg, fmt.Errorf("%s%s: %w", prefix, v.name, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// ConfigFromMap returns DefaultConfig overridden by the given values
+//
+// Keys are the names of the environment variables read by ConfigFromEnv
+// without prefix, in any case (e.g. "idle_timeout"), and the values are
+// parsed the same way. Unknown keys are rejected, so typos in config files
+// don't go unnoticed.
+func ConfigFromMap(values map[string]string) (Config, error) {
+	cfg := DefaultConfig()
+
+	vars := make(map[string]func(string) error)
+	for _, v := range cfg.vars() {
+		vars[v.name] = v.parse
+	}
+
+	for key, value := range values {
+		parse, ok := vars[strings.ToUpper(key)]
+		if !ok {
+			return cfg, fmt.Errorf("unknown option %q", key)
+		}
+
+		err := parse(value)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// configVar is a field of Config and the parser of its textual representation
+type configVar struct {
+	name  string
+	parse func(value string) error
+}
+
+// vars returns the fields of cfg by their environment variable names (without prefix)
+func (cfg *Config) vars() []configVar {
+	return []configVar{
+		{"WAIT_FOR_FILE", boolParser(&cfg.WaitForFile)},
+		{"WAIT_FOR_FILE_TIMEOUT", durationParser(&cfg.WaitForFileTimeout)},
+		{"WAIT_FOR_REGULAR_FILE", boolParser(&cfg.WaitForRegularFile)},
+		{"CLOSE_ON_DELETE", boolParser(&cfg.CloseOnDelete)},
+		{"DETECT_UNLINK", boolParser(&cfg.DetectUnlink)},
+		{"CLOSE_ON_TRUNCATE", boolParser(&cfg.CloseOnTruncate)},
+		{"SEEK_ON_TRUNCATE", boolParser(&cfg.SeekOnTruncate)},
+		{"IDLE_TIMEOUT", durationParser(&cfg.IdleTimeout)},
+		{"TIMEOUTS_AS_EOF", boolParser(&cfg.TreatTimeoutsAsEOF)},
+		{"MMAP_THRESHOLD", int64Parser(&cfg.MmapThreshold)},
+		{"FADVISE", boolParser(&cfg.Fadvise)},
+		{"NO_WAIT_READ", boolParser(&cfg.NoWaitRead)},
+		{"DIRECT_IO", boolParser(&cfg.DirectIO)},
+		{"SKIP_HOLES", boolParser(&cfg.SkipHoles)},
+		{"READAHEAD", intParser(&cfg.Readahead)},
+		{"PREFETCH", boolParser(&cfg.Prefetch)},
+		{"BUFFER_SIZE", intParser(&cfg.BufferSize)},
+		{"SPILL_DIR", stringParser(&cfg.SpillDir)},
+		{"MAX_SPILL_SIZE", int64Parser(&cfg.MaxSpillSize)},
+		{"STABILITY_WINDOW", durationParser(&cfg.StabilityWindow)},
+		{"GROWTH_LIMIT", int64Parser(&cfg.GrowthLimit)},
+		{"GROWTH_LIMIT_PERIOD", durationParser(&cfg.GrowthLimitPeriod)},
+		{"RATE_LIMIT", int64Parser(&cfg.RateLimit)},
+		{"CATCH_UP_RATE_LIMIT", int64Parser(&cfg.CatchUpRateLimit)},
+		{"CATCH_UP_READAHEAD", intParser(&cfg.CatchUpReadahead)},
+		{"CHECKSUM", stringParser((*string)(&cfg.Checksum))},
+		{"DETECT_MODIFICATION", boolParser(&cfg.DetectModification)},
+		{"IGNORE_CHMOD", boolParser(&cfg.IgnoreChmod)},
+		{"FOLLOW_MODE", stringParser((*string)(&cfg.FollowMode))},
+		{"FALLBACK_PATHS", pathListParser(&cfg.FallbackPaths)},
+		{"GLOB", boolParser(&cfg.Glob)},
+		{"CASE_INSENSITIVE", boolParser(&cfg.CaseInsensitive)},
+		{"RESOLVE_SYMLINKS", boolParser(&cfg.ResolveSymlinks)},
+		{"WATCH_FILE", boolParser(&cfg.WatchFile)},
+		{"WATCH_BUFFER_SIZE", intParser(&cfg.WatchBufferSize)},
+		{"NFS", boolParser(&cfg.NFS)},
+		{"POLL_INTERVAL", durationParser(&cfg.PollInterval)},
+		{"START_AT_END", boolParser(&cfg.StartAtEnd)},
+		{"LOCK_FILE", stringParser(&cfg.LockFile)},
+		{"RESPECT_WRITER_LOCK", boolParser(&cfg.RespectWriterLock)},
+	}
+}
+
+// Options returns the functional options equivalent to the config
+func (cfg Config) Options() []Option {
+	return []Option{
+		WithWaitForFile(cfg.WaitForFile, cfg.WaitForFileTimeout),
+		WithWaitForRegularFile(cfg.WaitForRegularFile),
+		WithCloseOnDelete(cfg.CloseOnDelete),
+		WithDetectUnlink(cfg.DetectUnlink),
+		WithCloseOnTruncate(cfg.CloseOnTruncate),
+		WithSeekOnTruncate(cfg.SeekOnTruncate),
+		WithIdleTimeout(cfg.IdleTimeout),
+		WithTimeoutsAsEOF(cfg.TreatTimeoutsAsEOF),
+		WithMmap(cfg.MmapThreshold),
+		WithFadvise(cfg.Fadvise),
+		WithNoWaitRead(cfg.NoWaitRead),
+		WithDirectIO(cfg.DirectIO),
+		WithSkipHoles(cfg.SkipHoles),
+		WithReadahead(cfg.Readahead),
+		WithPrefetch(cfg.Prefetch),
+		WithBufferSize(cfg.BufferSize),
+		WithSpill(cfg.SpillDir, cfg.MaxSpillSize),
+		WithStabilityWindow(cfg.StabilityWindow),
+		WithGrowthLimit(cfg.GrowthLimit, cfg.GrowthLimitPeriod),
+		WithRateLimit(cfg.RateLimit),
+		WithCatchUp(cfg.CatchUpRateLimit, cfg.CatchUpReadahead),
+		WithChecksum(cfg.Checksum),
+		WithDetectModification(cfg.DetectModification),
+		WithIgnoreChmod(cfg.IgnoreChmod),
+		WithFollowMode(cfg.FollowMode),
+		WithFallbackPaths(cfg.FallbackPaths...),
+		WithGlob(cfg.Glob),
+		WithCaseInsensitive(cfg.CaseInsensitive),
+		WithResolveSymlinks(cfg.ResolveSymlinks),
+		WithWatchFile(cfg.WatchFile),
+		WithWatchBufferSize(cfg.WatchBufferSize),
+		WithNFS(cfg.NFS),
+		WithPollInterval(cfg.PollInterval),
+		WithStartAtEnd(cfg.StartAtEnd),
+		WithLockFile(cfg.LockFile),
+		WithRespectWriterLock(cfg.RespectWriterLock),
+	}
+}
+
+// NewTailingReaderWithConfig creates a reader configured by cfg instead of functional options
+func NewTailingReaderWithConfig(filePath string, cfg Config) (*TailingReader, error) {
+	return NewTailingReader(filePath, cfg.Options()...)
+}
+
+func stringParser(v *string) func(string) error {
+	return func(value string) error {
+		*v = value
+		return nil
+	}
+}
+
+func boolParser(v *bool) func(string) error {
+	return func(value string) (err error) {
+		*v, err = strconv.ParseBool(value)
+		return err
+	}
+}
+
+func durationParser(v *time.Duration) func(string) error {
+	return func(value string) (err error) {
+		*v, err = time.ParseDuration(value)
+		return err
+	}
+}
+
+func int64Parser(v *int64) func(string) error {
+	return func(value string) (err error) {
+		*v, err = strconv.ParseInt(value, 10, 64)
+		return err
+	}
+}
+
+func intParser(v *int) func(string) error {
+	return func(value string) (err error) {
+		*v, err = strconv.Atoi(value)
+		return err
+	}
+}
+
+func pathListParser(v *[]string) func(string) error {
+	return func(value string) error {
+		*v = filepath.SplitList(value)
+		return nil
+	}
+}
@@ -0,0 +1,216 @@
+package tailreader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lengthPrefixSize is the size of the big-endian length preceding every record of LengthPrefixedDecoder
+const lengthPrefixSize = 4
+
+var ErrUnknownDecoder = fmt.Errorf("unknown decoder")
+
+var errRecordTooLarge = fmt.Errorf("record exceeds the maximum size")
+
+// Decoder turns the data of a certain type of file into records
+//
+// Files are read with the decoder's record options (e.g. a split function
+// for binary formats), and every record is passed to Decode, which returns
+// the decoded record or false to drop it. See Manager.AddDecoder.
+type Decoder interface {
+	RecordOptions() []RecordOption
+	Decode(rec Record) (Record, bool)
+}
+
+// LineDecoder passes lines through as they are, which is the default
+type LineDecoder struct{}
+
+func (LineDecoder) RecordOptions() []RecordOption {
+	return nil
+}
+
+func (LineDecoder) Decode(rec Record) (Record, bool) {
+	return rec, true
+}
+
+// JSONLinesDecoder decodes JSON Lines, dropping blank lines
+//
+// The data of records stays the JSON object, but their timestamp and level
+// are taken from the given fields (if set). Timestamps are RFC 3339 strings
+// or numbers of seconds since the epoch.
+type JSONLinesDecoder struct {
+	TimeField  string
+	LevelField string
+}
+
+func (d *JSONLinesDecoder) RecordOptions() []RecordOption {
+	return nil
+}
+
+func (d *JSONLinesDecoder) Decode(rec Record) (Record, bool) {
+	rec.Data = bytes.TrimSpace(rec.Data)
+	if len(rec.Data) == 0 {
+		return rec, false
+	}
+	if d.TimeField == "" && d.LevelField == "" {
+		return rec, true
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(rec.Data, &fields) != nil {
+		return rec, true
+	}
+
+	if value, ok := fields[d.TimeField]; ok && d.TimeField != "" {
+		if ts, ok := jsonTime(value); ok {
+			rec.Timestamp = ts
+		}
+	}
+	if value, ok := fields[d.LevelField]; ok && d.LevelField != "" {
+		var name string
+		if json.Unmarshal(value, &name) == nil {
+			if level, ok := ParseLevel(name); ok {
+				rec.Level = level
+			}
+		}
+	}
+	return rec, true
+}
+
+// jsonTime parses an RFC 3339 string or a number of seconds since the epoch
+func jsonTime(value json.RawMessage) (time.Time, bool) {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		return ts, err == nil
+	}
+
+	var seconds float64
+	if json.Unmarshal(value, &seconds) == nil {
+		return time.Unix(0, int64(seconds*1e9)), true
+	}
+	return time.Time{}, false
+}
+
+// DockerDecoder decodes the logs of Docker's json-file logging driver
+//
+// The data of records is the logged line, their timestamp the time Docker
+// logged it, and they're labeled with their stream ("stdout" or "stderr").
+// Lines Docker split (beyond 16 KiB) are delivered as separate records. Lines
+// that aren't in Docker's format are passed through.
+type DockerDecoder struct{}
+
+func (DockerDecoder) RecordOptions() []RecordOption {
+	return nil
+}
+
+func (DockerDecoder) Decode(rec Record) (Record, bool) {
+	var entry struct {
+		Log    *string   `json:"log"`
+		Stream string    `json:"stream"`
+		Time   time.Time `json:"time"`
+	}
+	if json.Unmarshal(rec.Data, &entry) != nil || entry.Log == nil {
+		return rec, true
+	}
+
+	rec.Data = []byte(strings.TrimSuffix(*entry.Log, "\n"))
+	if !entry.Time.IsZero() {
+		rec.Timestamp = entry.Time
+	}
+	if entry.Stream != "" {
+		labels := maps.Clone(rec.Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels["stream"] = entry.Stream
+		rec.Labels = labels
+	}
+	return rec, true
+}
+
+// LengthPrefixedDecoder decodes binary records, each preceded by its length as 32-bit big-endian integer
+type LengthPrefixedDecoder struct {
+	// MaxSize is the maximum size of a record, larger ones fail reading; 0 uses DefaultMaxRecordSize
+	MaxSize int
+}
+
+func (d *LengthPrefixedDecoder) RecordOptions() []RecordOption {
+	maxSize := d.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxRecordSize
+	}
+
+	split := func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) < lengthPrefixSize {
+			return 0, nil, nil
+		}
+
+		size := binary.BigEndian.Uint32(data)
+		if uint64(size) > uint64(maxSize) {
+			return 0, nil, fmt.Errorf("%w: %d bytes", errRecordTooLarge, size)
+		}
+
+		end := lengthPrefixSize + int(size)
+		if len(data) < end {
+			return 0, nil, nil
+		}
+		return end, data[lengthPrefixSize:end], nil
+	}
+
+	return []RecordOption{WithSplitFunc(split)}
+}
+
+func (d *LengthPrefixedDecoder) Decode(rec Record) (Record, bool) {
+	return rec, true
+}
+
+// NewDecoder returns the built-in decoder of the given name
+//
+// The names are "lines", "jsonl" (with the fields "time" and "level"),
+// "docker" and "length-prefixed".
+func NewDecoder(name string) (Decoder, error) {
+	switch name {
+	case "lines":
+		return LineDecoder{}, nil
+	case "jsonl":
+		return &JSONLinesDecoder{TimeField: "time", LevelField: "level"}, nil
+	case "docker":
+		return DockerDecoder{}, nil
+	case "length-prefixed":
+		return &LengthPrefixedDecoder{}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownDecoder, name)
+}
+
+// patternDecoder is the decoder of files matching a pattern
+type patternDecoder struct {
+	pattern string
+	decoder Decoder
+}
+
+// matches checks whether path matches the pattern, whose base name is matched if it has no directory
+func (d patternDecoder) matches(path string) bool {
+	if !strings.ContainsRune(d.pattern, '/') && !strings.ContainsRune(d.pattern, filepath.Separator) {
+		path = filepath.Base(path)
+	}
+	ok, _ := filepath.Match(d.pattern, path)
+	return ok
+}
+
+// decodeBatch decodes the records of batch in place, dropping those the decoder drops
+func decodeBatch(decoder Decoder, batch []Record) []Record {
+	decoded := batch[:0]
+	for _, rec := range batch {
+		if rec, ok := decoder.Decode(rec); ok {
+			decoded = append(decoded, rec)
+		}
+	}
+	return decoded
+}
@@ -0,0 +1,89 @@
+package tailreader
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// DedupReader suppresses consecutive identical records of a RecordSource
+//
+// Like syslog's duplicate suppression, repeated records are replaced by a
+// synthetic record "last message repeated N times", which is returned before
+// the next different record (or io.EOF). The synthetic record has the path,
+// offset and time of the last repetition.
+type DedupReader struct {
+	src      RecordSource
+	window   time.Duration
+	first    Record  // first occurrence of the record repetitions are compared to
+	seen     bool    // whether first is set
+	last     Record  // last repetition
+	repeated int     // number of repetitions not yet reported
+	pending  *Record // record to return after the synthetic one
+	err      error   // error to return after the synthetic one
+}
+
+// NewDedupReader returns a DedupReader
+//
+// If window is non-zero, a record only counts as a repetition within window
+// of the first occurrence; after that, it's returned again.
+func NewDedupReader(src RecordSource, window time.Duration) *DedupReader {
+	return &DedupReader{
+		src:    src,
+		window: window,
+	}
+}
+
+// ReadRecord returns the next record that isn't a repetition of the previous one
+func (d *DedupReader) ReadRecord() (Record, error) {
+	if d.pending != nil {
+		rec := *d.pending
+		d.pending = nil
+		return rec, nil
+	}
+	if d.err != nil {
+		err := d.err
+		d.err = nil
+		return Record{}, err
+	}
+
+	for {
+		rec, err := d.src.ReadRecord()
+		if err != nil {
+			if d.repeated > 0 {
+				d.err = err
+				return d.summary(), nil
+			}
+			return rec, err
+		}
+
+		if d.isRepetition(rec) {
+			d.repeated++
+			d.last = rec
+			continue
+		}
+
+		d.first, d.seen = rec, true
+		if d.repeated > 0 {
+			d.pending = &rec
+			return d.summary(), nil
+		}
+		return rec, nil
+	}
+}
+
+// isRepetition checks whether rec repeats the first occurrence
+func (d *DedupReader) isRepetition(rec Record) bool {
+	if !d.seen || !bytes.Equal(rec.Data, d.first.Data) {
+		return false
+	}
+	return d.window == 0 || rec.Time.Sub(d.first.Time) < d.window
+}
+
+// summary returns the synthetic record reporting the repetitions and resets their count
+func (d *DedupReader) summary() Record {
+	rec := d.last
+	rec.Data = fmt.Appendf(nil, "last message repeated %d times", d.repeated)
+	d.repeated = 0
+	return rec
+}
@@ -0,0 +1,61 @@
+package tailreader
+
+import (
+	"io"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// directIOAlignment is the alignment of buffer addresses, file offsets and lengths for O_DIRECT
+	directIOAlignment = 4096
+
+	// directIOBufferSize is the size of the aligned read buffer
+	directIOBufferSize = 64 * 1024
+)
+
+func (r *TailingReader) openFlags() int {
+	if r.options.DirectIO {
+		return unix.O_DIRECT
+	}
+	return 0
+}
+
+// readDirect reads at the current offset using an aligned intermediate buffer
+//
+// The read starts at the offset rounded down to the alignment; the already
+// consumed bytes of the first block are skipped when copying to p.
+func (r *TailingReader) readDirect(p []byte) (int, error) {
+	if r.directBuf == nil {
+		r.directBuf = alignedBuffer(directIOBufferSize, directIOAlignment)
+	}
+
+	start := r.offset &^ (directIOAlignment - 1)
+	skip := int(r.offset - start)
+
+	length := (skip + len(p) + directIOAlignment - 1) &^ (directIOAlignment - 1)
+	if length > len(r.directBuf) {
+		length = len(r.directBuf)
+	}
+
+	n, err := unix.Pread(int(r.osFile.Fd()), r.directBuf[:length], start)
+	if err != nil {
+		return 0, err
+	}
+
+	if n <= skip {
+		return 0, io.EOF
+	}
+
+	return copy(p, r.directBuf[skip:n]), nil
+}
+
+func alignedBuffer(size, alignment int) []byte {
+	buf := make([]byte, size+alignment)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&buf[0])) & uintptr(alignment-1)); rem != 0 {
+		offset = alignment - rem
+	}
+	return buf[offset : offset+size]
+}
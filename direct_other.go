@@ -0,0 +1,11 @@
+//go:build !linux
+
+package tailreader
+
+func (r *TailingReader) openFlags() int {
+	return 0
+}
+
+func (r *TailingReader) readDirect(p []byte) (int, error) {
+	return r.file.Read(p)
+}
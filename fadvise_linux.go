@@ -0,0 +1,21 @@
+package tailreader
+
+import "golang.org/x/sys/unix"
+
+// fadviseChunkSize is the minimum amount of consumed data before pages are dropped from the page cache
+const fadviseChunkSize = 1 << 20
+
+func (r *TailingReader) adviseSequential() {
+	_ = unix.Fadvise(int(r.osFile.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+}
+
+func (r *TailingReader) adviseConsumed() {
+	if r.offset-r.advisedOffset < fadviseChunkSize {
+		return
+	}
+
+	err := unix.Fadvise(int(r.osFile.Fd()), 0, r.offset, unix.FADV_DONTNEED)
+	if err == nil {
+		r.advisedOffset = r.offset
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package tailreader
+
+func (r *TailingReader) adviseSequential() {}
+
+func (r *TailingReader) adviseConsumed() {}
@@ -0,0 +1,89 @@
+package tailreader
+
+import "path/filepath"
+
+// failover switches to the first existing candidate path if the current file is missing or unreadable
+//
+// Candidates are the path passed to the constructor followed by FallbackPaths,
+// which are glob patterns if Glob is set. It returns whether the reader
+// switched to a different file.
+func (r *TailingReader) failover() (bool, error) {
+	if len(r.paths) == 0 {
+		return false, nil
+	}
+	if info, err := r.fs.Stat(r.filePath); err == nil && !info.IsDir() {
+		return false, nil
+	}
+
+	path, err := r.findCandidate()
+	if err != nil || path == "" {
+		return false, err
+	}
+
+	_ = r.closeFile()
+	r.filePath = path
+	r.offset = 0
+	r.pending = nil
+	r.updateWatch()
+	if r.polling {
+		r.pollFile()
+	}
+	return true, nil
+}
+
+// failoverAvailable checks whether a candidate other than the current file exists
+func (r *TailingReader) failoverAvailable() bool {
+	path, _ := r.findCandidate()
+	return path != ""
+}
+
+// findCandidate returns the first existing candidate path in order, or "" if that's the current file (or there's none)
+func (r *TailingReader) findCandidate() (string, error) {
+	for _, path := range r.paths {
+		if r.options.Glob {
+			matches, err := r.glob(path)
+			if err != nil {
+				return "", err
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			path = matches[0]
+		} else if info, err := r.fs.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+
+		if path == r.filePath {
+			return "", nil
+		}
+		return path, nil
+	}
+	return "", nil
+}
+
+// isCandidate checks whether name is (or matches) one of the candidate paths
+func (r *TailingReader) isCandidate(name string) bool {
+	name = r.eventPath(name)
+	for _, path := range r.paths {
+		path = r.eventPath(path)
+		if path == name {
+			return true
+		}
+		if r.options.Glob {
+			if ok, _ := filepath.Match(path, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCandidateDir checks whether dir contains one of the candidate paths
+func (r *TailingReader) isCandidateDir(dir string) bool {
+	for _, path := range r.paths {
+		if filepath.Dir(path) == dir {
+			return true
+		}
+	}
+	return false
+}
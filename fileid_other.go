@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tailreader
+
+import "io/fs"
+
+func fileIDOf(info fs.FileInfo) string {
+	return ""
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package tailreader
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// fileIDOf returns the device and inode of the file, or "" if info isn't of an operating system file
+func fileIDOf(info fs.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino)
+}
@@ -0,0 +1,32 @@
+package tailreader
+
+import "regexp"
+
+// FilterReader passes through the records of a RecordSource that match a regular expression
+//
+// Records are matched as a whole after line assembly, without the trailing
+// newline, so a match never spans multiple lines.
+type FilterReader struct {
+	src    RecordSource
+	re     *regexp.Regexp
+	invert bool
+}
+
+// NewFilterReader returns a FilterReader passing records matching re, or those not matching it if invert is set
+func NewFilterReader(src RecordSource, re *regexp.Regexp, invert bool) *FilterReader {
+	return &FilterReader{
+		src:    src,
+		re:     re,
+		invert: invert,
+	}
+}
+
+// ReadRecord returns the next record passing the filter
+func (f *FilterReader) ReadRecord() (Record, error) {
+	for {
+		rec, err := f.src.ReadRecord()
+		if err != nil || f.re.Match(rec.Data) != f.invert {
+			return rec, err
+		}
+	}
+}
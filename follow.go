@@ -0,0 +1,47 @@
+package tailreader
+
+import "io/fs"
+
+// FollowMode is what the reader follows when the file is renamed or deleted, like GNU tail's --follow
+type FollowMode string
+
+const (
+	// FollowName reopens the path once a file is (re)created there, which is the default
+	//
+	// Together with WaitForFile, this retries until the file becomes accessible
+	// again, e.g. after log rotation.
+	FollowName FollowMode = "name"
+
+	// FollowDescriptor keeps reading the open file after it was renamed or deleted
+	//
+	// As there are no notifications for a file that is no longer at the watched
+	// path, it is polled for changes (see PollInterval) from then on.
+	FollowDescriptor FollowMode = "descriptor"
+)
+
+func (m FollowMode) valid() bool {
+	return m == "" || m == FollowName || m == FollowDescriptor
+}
+
+// detach keeps following the open file after its path was removed or renamed
+//
+// It returns false if the file isn't followed by descriptor.
+func (r *TailingReader) detach() bool {
+	if r.options.FollowMode != FollowDescriptor || r.file == nil {
+		return false
+	}
+
+	if !r.detached {
+		r.detached = true
+		r.pollFile()
+	}
+	return true
+}
+
+// statFile returns the file info of the open file if it was detached from its path, or of the path otherwise
+func (r *TailingReader) statFile() (fs.FileInfo, error) {
+	if r.detached {
+		return r.file.Stat()
+	}
+	return r.fs.Stat(r.filePath)
+}
@@ -0,0 +1,105 @@
+package tailreader
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// FS is the file system used to access the tailed file
+//
+// A thin adapter around afero.Fs (or any similar abstraction) satisfies this
+// interface, so truncation, deletion and rotation can be simulated in memory.
+// OpenFile is only called with read-only flags; files that are not *os.File
+// don't support features like WithMmap or WithDirectIO.
+type FS interface {
+	Stat(name string) (fs.FileInfo, error)
+	OpenFile(name string, flag int) (fs.File, error)
+}
+
+// VersionedFileInfo is implemented by file infos carrying a content version,
+// e.g. the ETag of a file served over HTTP or the generation of a stored object
+//
+// A changed version without the file's size changing means the file was
+// rewritten in place, which is handled like a truncation.
+type VersionedFileInfo interface {
+	fs.FileInfo
+	Version() string
+}
+
+// fileVersion returns the version of a VersionedFileInfo, or ""
+func fileVersion(info fs.FileInfo) string {
+	if versioned, ok := info.(VersionedFileInfo); ok {
+		return versioned.Version()
+	}
+	return ""
+}
+
+// StatFunc returns the file info of the named file, see os.Stat
+type StatFunc func(name string) (fs.FileInfo, error)
+
+// OpenFunc opens the named file with the given flags, see os.OpenFile
+type OpenFunc func(name string, flag int) (fs.File, error)
+
+// hookFS overrides Stat and/or OpenFile of the underlying FS
+type hookFS struct {
+	FS
+	stat StatFunc
+	open OpenFunc
+}
+
+func (f hookFS) Stat(name string) (fs.FileInfo, error) {
+	if f.stat != nil {
+		return f.stat(name)
+	}
+	return f.FS.Stat(name)
+}
+
+func (f hookFS) OpenFile(name string, flag int) (fs.File, error) {
+	if f.open != nil {
+		return f.open(name, flag)
+	}
+	return f.FS.OpenFile(name, flag)
+}
+
+func (r *TailingReader) setFS(fsys FS) {
+	if r.options.StatFunc != nil || r.options.OpenFunc != nil {
+		fsys = hookFS{FS: fsys, stat: r.options.StatFunc, open: r.options.OpenFunc}
+	}
+	r.fs = fsys
+}
+
+// osFS is the operating system's file system
+type osFS struct{}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(longPath(name))
+}
+
+func (osFS) OpenFile(name string, flag int) (fs.File, error) {
+	return os.OpenFile(longPath(name), flag, 0)
+}
+
+// ioFS adapts an fs.FS; open flags are ignored
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(f.fsys, name)
+}
+
+func (f ioFS) OpenFile(name string, flag int) (fs.File, error) {
+	return f.fsys.Open(name)
+}
+
+// seekFile moves to offset, discarding data if the file isn't seekable
+func seekFile(file fs.File, offset int64) error {
+	if seeker, ok := file.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, file, offset)
+	return err
+}
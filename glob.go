@@ -0,0 +1,54 @@
+package tailreader
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+var ErrGlobUnsupported = fmt.Errorf("file system doesn't support glob patterns")
+
+// globFS is implemented by file systems that can list the files matching a pattern
+type globFS interface {
+	Glob(pattern string) ([]string, error)
+}
+
+func (osFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (f ioFS) Glob(pattern string) ([]string, error) {
+	return fs.Glob(f.fsys, pattern)
+}
+
+func (f hookFS) Glob(pattern string) ([]string, error) {
+	return globFile(f.FS, pattern)
+}
+
+// glob returns the names of all files matching pattern in lexical order
+func (r *TailingReader) glob(pattern string) ([]string, error) {
+	return globFile(r.fs, pattern)
+}
+
+func globFile(fsys FS, pattern string) ([]string, error) {
+	globber, ok := fsys.(globFS)
+	if !ok {
+		return nil, ErrGlobUnsupported
+	}
+	return globber.Glob(pattern)
+}
+
+// validatePattern checks that pattern is valid and only the file name contains wildcards
+func validatePattern(pattern string) error {
+	_, err := filepath.Match(pattern, "")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+
+	if strings.ContainsAny(filepath.Dir(pattern), "*?[") {
+		// the directory has to be watched for the file to be created
+		return fmt.Errorf("%w: wildcards are only supported in the file name", ErrInvalidOptions)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tailreader
+
+import (
+	"fmt"
+	"time"
+)
+
+// growthSampleInterval is the minimum time between two measurements of the file's growth rate
+const growthSampleInterval = time.Second
+
+// ErrGrowthLimit is returned by Read if the file grew faster than GrowthLimit for GrowthLimitPeriod
+//
+// It's returned once per period of excessive growth; a subsequent Read continues reading.
+var ErrGrowthLimit = fmt.Errorf("growth limit exceeded")
+
+// checkGrowth measures the growth rate of the file and reports if it's exceeded GrowthLimit for GrowthLimitPeriod
+//
+// The excess is reported via OnGrowthLimit if set, or as error otherwise.
+func (r *TailingReader) checkGrowth(size int64) error {
+	if r.options.GrowthLimit <= 0 {
+		return nil
+	}
+
+	now := r.now()
+	if r.growthTime.IsZero() || size < r.growthSize {
+		// first measurement or the file was truncated
+		r.growthSize, r.growthTime = size, now
+		r.growingSince = time.Time{}
+		return nil
+	}
+
+	elapsed := now.Sub(r.growthTime)
+	if elapsed < growthSampleInterval {
+		return nil
+	}
+
+	rate := int64(float64(size-r.growthSize) / elapsed.Seconds())
+	if rate <= r.options.GrowthLimit {
+		r.growingSince = time.Time{}
+		r.growthReported = false
+	} else if r.growingSince.IsZero() {
+		r.growingSince = r.growthTime
+	}
+	r.growthSize, r.growthTime = size, now
+
+	if r.growingSince.IsZero() || r.growthReported || now.Sub(r.growingSince) < r.options.GrowthLimitPeriod {
+		return nil
+	}
+
+	r.growthReported = true
+	if r.options.OnGrowthLimit != nil {
+		r.options.OnGrowthLimit(rate)
+		return nil
+	}
+	return fmt.Errorf("%w: %d bytes/s for %s", ErrGrowthLimit, rate, now.Sub(r.growingSince))
+}
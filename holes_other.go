@@ -0,0 +1,7 @@
+//go:build !(linux || darwin || freebsd)
+
+package tailreader
+
+func (r *TailingReader) skipHole() error {
+	return nil
+}
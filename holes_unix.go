@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd
+
+package tailreader
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/sys/unix"
+)
+
+// skipHole moves the offset to the start of the next data region if it currently points into a hole
+func (r *TailingReader) skipHole() error {
+	offset, err := unix.Seek(int(r.osFile.Fd()), r.offset, unix.SEEK_DATA)
+	if errors.Is(err, unix.ENXIO) {
+		// no more data after the current offset
+		return io.EOF
+	}
+	if err != nil {
+		// file system doesn't support SEEK_DATA, just read the hole
+		return nil
+	}
+
+	if offset > r.offset {
+		r.offset = offset
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package tailreader
+
+import "time"
+
+// IdleAction is what Read does once IdleTimeout is reached, see OnIdleTimeout
+type IdleAction int
+
+const (
+	// IdleContinue keeps waiting for another IdleTimeout
+	IdleContinue IdleAction = iota
+
+	// IdleReturnError makes Read return ErrIdleTimeout
+	IdleReturnError
+
+	// IdleReturnEOF makes Read return io.EOF
+	IdleReturnEOF
+)
+
+// idleAction returns what to do once the reader has been waiting for data for idle
+func (r *TailingReader) idleAction(idle time.Duration) IdleAction {
+	if r.options.OnIdleTimeout != nil {
+		return r.options.OnIdleTimeout(idle)
+	}
+	if r.options.TreatTimeoutsAsEOF {
+		return IdleReturnEOF
+	}
+	return IdleReturnError
+}
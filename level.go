@@ -0,0 +1,152 @@
+package tailreader
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Level is the severity of a record, ordered from the least to the most severe
+type Level int
+
+const (
+	LevelNone Level = iota // the record has no (known) level
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = []string{"", "TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return ""
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses the common names of levels case-insensitively, e.g. "warning" or "E"
+func ParseLevel(s string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace", "trc", "t":
+		return LevelTrace, true
+	case "debug", "dbg", "d":
+		return LevelDebug, true
+	case "info", "information", "informational", "inf", "notice", "i":
+		return LevelInfo, true
+	case "warn", "warning", "wrn", "w":
+		return LevelWarn, true
+	case "error", "err", "e":
+		return LevelError, true
+	case "fatal", "critical", "crit", "panic", "alert", "emergency", "emerg", "f":
+		return LevelFatal, true
+	}
+	return LevelNone, false
+}
+
+// LevelExtractor extracts the level of a record, returning false if it has none
+//
+// A LevelParser is a LevelExtractor, so any function can be used.
+type LevelExtractor interface {
+	ExtractLevel(data []byte) (Level, bool)
+}
+
+// LevelParser extracts the level of a record, returning false if it has none
+type LevelParser func(data []byte) (Level, bool)
+
+func (p LevelParser) ExtractLevel(data []byte) (Level, bool) {
+	return p(data)
+}
+
+// RegexpLevelExtractor extracts levels found by a regular expression
+type RegexpLevelExtractor struct {
+	// Regexp finds the level, which is its first submatch or (without submatches) the whole match
+	Regexp *regexp.Regexp
+}
+
+// NewRegexpLevelExtractor creates a RegexpLevelExtractor for levels matching pattern, e.g. `level=(\w+)`
+func NewRegexpLevelExtractor(pattern string) (*RegexpLevelExtractor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpLevelExtractor{Regexp: re}, nil
+}
+
+func (e *RegexpLevelExtractor) ExtractLevel(data []byte) (Level, bool) {
+	match := e.Regexp.FindSubmatch(data)
+	if match == nil {
+		return LevelNone, false
+	}
+
+	value := match[0]
+	if len(match) > 1 {
+		value = match[1]
+	}
+	return ParseLevel(string(value))
+}
+
+// JSONLevelExtractor extracts levels from a field of records that are JSON objects
+type JSONLevelExtractor struct {
+	Field string // name of the field, e.g. "level" or "severity"
+}
+
+func (e *JSONLevelExtractor) ExtractLevel(data []byte) (Level, bool) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return LevelNone, false
+	}
+
+	var value string
+	if json.Unmarshal(fields[e.Field], &value) != nil {
+		return LevelNone, false
+	}
+	return ParseLevel(value)
+}
+
+// WithLevelExtractor sets the extractor of the levels of records (see Record.Level)
+func WithLevelExtractor(extractor LevelExtractor) RecordOption {
+	return func(rr *RecordReader) {
+		rr.levelExtractor = extractor
+	}
+}
+
+// level returns the level of data, or LevelNone if it has none
+func (rr *RecordReader) level(data []byte) Level {
+	if rr.levelExtractor != nil {
+		if level, ok := rr.levelExtractor.ExtractLevel(data); ok {
+			return level
+		}
+	}
+	return LevelNone
+}
+
+// LevelFilterReader passes through the records of a RecordSource with at least a minimum level
+//
+// Records without a level (LevelNone) are passed, as they cannot be judged;
+// e.g. continuation lines of a stack trace.
+type LevelFilterReader struct {
+	src RecordSource
+	min Level
+}
+
+// NewLevelFilterReader returns a LevelFilterReader passing records of at least level min
+func NewLevelFilterReader(src RecordSource, min Level) *LevelFilterReader {
+	return &LevelFilterReader{
+		src: src,
+		min: min,
+	}
+}
+
+// ReadRecord returns the next record passing the filter
+func (f *LevelFilterReader) ReadRecord() (Record, error) {
+	for {
+		rec, err := f.src.ReadRecord()
+		if err != nil || rec.Level == LevelNone || rec.Level >= f.min {
+			return rec, err
+		}
+	}
+}
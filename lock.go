@@ -0,0 +1,84 @@
+package tailreader
+
+import (
+	"fmt"
+	"os"
+)
+
+var ErrLockUnsupported = fmt.Errorf("lock files are not supported on this platform")
+
+// acquireLock waits until the lock file is locked by this reader
+//
+// Attempts are repeated every poll interval; the lock is released on Close.
+// Once locked, reading continues at the checkpoint saved by the previous
+// holder of the lock (if a CheckpointStore is set), which hands over tailing
+// to a standby process when the active one dies.
+func (r *TailingReader) acquireLock() error {
+	if r.options.LockFile == "" || r.lockFile != nil {
+		return nil
+	}
+
+	file, err := os.OpenFile(r.options.LockFile, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+
+		if locked {
+			r.lockFile = file
+			return r.resumeFromCheckpoint()
+		}
+
+		err = r.sleep(r.pollInterval())
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+}
+
+// resumeFromCheckpoint continues at the offset committed by the previous holder of the lock
+func (r *TailingReader) resumeFromCheckpoint() error {
+	cp, ok, err := r.loadCheckpoint()
+	if err != nil || !ok {
+		return err
+	}
+
+	return r.restoreCheckpoint(cp)
+}
+
+// releaseLock releases the lock file (if it's locked)
+func (r *TailingReader) releaseLock() error {
+	if r.lockFile == nil {
+		return nil
+	}
+
+	err := r.lockFile.Close()
+	r.lockFile = nil
+	return err
+}
+
+// lockForRead takes a shared advisory lock on the file if RespectWriterLock is set
+//
+// It returns false if a writer holds an exclusive lock, in which case reading
+// has to wait. The lock must be released by unlockAfterRead.
+func (r *TailingReader) lockForRead() (bool, error) {
+	if !r.options.RespectWriterLock || r.osFile == nil {
+		return true, nil
+	}
+	return tryLockShared(r.osFile)
+}
+
+// unlockAfterRead releases the lock taken by lockForRead
+func (r *TailingReader) unlockAfterRead() {
+	if !r.options.RespectWriterLock || r.osFile == nil {
+		return
+	}
+	_ = unlock(r.osFile)
+}
@@ -0,0 +1,17 @@
+//go:build !unix && !windows
+
+package tailreader
+
+import "os"
+
+func tryLock(file *os.File) (bool, error) {
+	return false, ErrLockUnsupported
+}
+
+func tryLockShared(file *os.File) (bool, error) {
+	return false, ErrLockUnsupported
+}
+
+func unlock(file *os.File) error {
+	return ErrLockUnsupported
+}
@@ -0,0 +1,46 @@
+//go:build unix
+
+package tailreader
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// tryLock takes an exclusive advisory lock on file without blocking
+func tryLock(file *os.File) (bool, error) {
+	return flock(file, unix.LOCK_EX|unix.LOCK_NB)
+}
+
+// tryLockShared takes a shared advisory lock on file without blocking
+func tryLockShared(file *os.File) (bool, error) {
+	return flock(file, unix.LOCK_SH|unix.LOCK_NB)
+}
+
+// unlock releases a lock taken by tryLockShared
+func unlock(file *os.File) error {
+	_, err := flock(file, unix.LOCK_UN)
+	return err
+}
+
+func flock(file *os.File, how int) (bool, error) {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var lockErr error
+	err = conn.Control(func(fd uintptr) {
+		lockErr = unix.Flock(int(fd), how)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if errors.Is(lockErr, unix.EWOULDBLOCK) {
+		return false, nil
+	}
+	return lockErr == nil, lockErr
+}
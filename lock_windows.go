@@ -0,0 +1,59 @@
+//go:build windows
+
+package tailreader
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// tryLock takes an exclusive lock on file without blocking
+func tryLock(file *os.File) (bool, error) {
+	return lockFileEx(file, windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 1, 0)
+}
+
+// tryLockShared takes a shared lock on the whole file without blocking
+func tryLockShared(file *os.File) (bool, error) {
+	return lockFileEx(file, windows.LOCKFILE_FAIL_IMMEDIATELY, math.MaxUint32, math.MaxUint32)
+}
+
+// unlock releases a lock taken by tryLockShared
+func unlock(file *os.File) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var unlockErr error
+	err = conn.Control(func(fd uintptr) {
+		unlockErr = windows.UnlockFileEx(windows.Handle(fd), 0, math.MaxUint32, math.MaxUint32, &windows.Overlapped{})
+	})
+	if err != nil {
+		return err
+	}
+	return unlockErr
+}
+
+// lockFileEx locks the given number of bytes at the beginning of file
+func lockFileEx(file *os.File, flags uint32, low uint32, high uint32) (bool, error) {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var lockErr error
+	err = conn.Control(func(fd uintptr) {
+		lockErr = windows.LockFileEx(windows.Handle(fd), flags, 0, low, high, &windows.Overlapped{})
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if errors.Is(lockErr, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return lockErr == nil, lockErr
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package tailreader
+
+// longPath returns path unchanged; only Windows limits the length of paths
+func longPath(path string) string {
+	return path
+}
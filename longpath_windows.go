@@ -0,0 +1,30 @@
+//go:build windows
+
+package tailreader
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxPath is the length from which paths are converted to their extended-length form;
+// MAX_PATH is 260, but directories are limited to 248 characters (room for an 8.3 name)
+const maxPath = 248
+
+// longPath returns path in its extended-length form (\\?\C:\... or \\?\UNC\server\share\...)
+// if it exceeds the legacy MAX_PATH limit, and path unchanged otherwise
+func longPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) || len(path) < maxPath && filepath.IsAbs(path) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil || len(abs) < maxPath {
+		return path
+	}
+
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
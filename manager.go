@@ -0,0 +1,591 @@
+package tailreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManagerConfig is the declarative configuration of the files tailed by a Manager
+//
+// It can be parsed from YAML or JSON (see ParseManagerConfig), so embedding
+// applications can expose it to operators directly. Options are given by
+// their names as accepted by ConfigFromMap, e.g. "idle_timeout: 1m".
+type ManagerConfig struct {
+	Options map[string]string `yaml:"options" json:"options"` // options for all files
+	Labels  map[string]string `yaml:"labels" json:"labels"`   // labels of all files, see WithLabels
+	Files   []ManagerFile     `yaml:"files" json:"files"`
+
+	// Decoders assigns decoders to files by pattern, see Manager.AddDecoder
+	Decoders []ManagerDecoder `yaml:"decoders" json:"decoders"`
+}
+
+// ManagerDecoder is a decoder of a ManagerConfig
+type ManagerDecoder struct {
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Decoder string `yaml:"decoder" json:"decoder"` // name of a built-in decoder, see NewDecoder
+}
+
+// ManagerFile is a file of a ManagerConfig
+//
+// If the path is a glob pattern, every matching file is tailed.
+type ManagerFile struct {
+	Path    string            `yaml:"path" json:"path"`
+	Options map[string]string `yaml:"options" json:"options"` // overrides ManagerConfig.Options
+	Sinks   []string          `yaml:"sinks" json:"sinks"`     // names of the sinks to write to, all if empty
+	Labels  map[string]string `yaml:"labels" json:"labels"`   // added to (or overriding) ManagerConfig.Labels
+}
+
+// ParseManagerConfig parses a ManagerConfig from YAML or JSON
+func ParseManagerConfig(data []byte) (ManagerConfig, error) {
+	var cfg ManagerConfig
+	err := yaml.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// LoadManagerConfig reads a ManagerConfig from a YAML or JSON file
+func LoadManagerConfig(path string) (ManagerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ManagerConfig{}, err
+	}
+
+	cfg, err := ParseManagerConfig(data)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// FileOptions returns the options of every file (or pattern) by its path
+func (cfg ManagerConfig) FileOptions() (map[string][]Option, error) {
+	files := make(map[string][]Option, len(cfg.Files))
+	for _, f := range cfg.Files {
+		if f.Path == "" {
+			return nil, fmt.Errorf("file without path")
+		}
+
+		values := maps.Clone(cfg.Options)
+		if values == nil {
+			values = make(map[string]string)
+		}
+		maps.Copy(values, f.Options)
+
+		c, err := ConfigFromMap(values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		var labels map[string]string
+		if len(cfg.Labels) > 0 || len(f.Labels) > 0 {
+			labels = maps.Clone(cfg.Labels)
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			maps.Copy(labels, f.Labels)
+		}
+
+		files[f.Path] = append(c.Options(), WithLabels(labels))
+	}
+
+	return files, nil
+}
+
+// managerBatchSize and managerBatchWait limit the batches of records written to the sinks before flushing them
+const (
+	managerBatchSize = 1000
+	managerBatchWait = 100 * time.Millisecond
+)
+
+var errManagerRunning = fmt.Errorf("manager is already running")
+
+// errManagerClosed ends a run because the Manager was closed
+var errManagerClosed = fmt.Errorf("manager closed")
+
+// Manager maintains the readers of a set of files and delivers their records to sinks
+//
+// The files are added and removed individually or by applying a ManagerConfig,
+// which starts, updates and stops readers to match it. While Run is running,
+// the records of every file are written to the sinks assigned to it.
+type Manager struct {
+	options []Option // options for all readers, applied after their own
+
+	mu     sync.Mutex
+	files  map[string]*managedFile // files by path
+	sinks  map[string]*managedSink // sinks by name
+	closed bool
+
+	recordOptions []RecordOption   // options of the RecordReaders of all files
+	decoders      []patternDecoder // added by AddDecoder, in order of precedence
+	cfgDecoders   []patternDecoder // of the applied ManagerConfig
+
+	walDir     string // directory of the write-ahead logs of the sinks, empty if not used
+	walMaxSize int64
+
+	runCtx    context.Context // context of the current run, nil if not running
+	runCancel context.CancelCauseFunc
+	pumps     sync.WaitGroup
+	drains    sync.WaitGroup
+}
+
+// managedFile is a file tailed by a Manager
+type managedFile struct {
+	tr    *TailingReader
+	sinks []string // names of the sinks to write to, all if empty
+}
+
+// NewManager creates a Manager whose readers all get the given options (e.g. a checkpoint store)
+func NewManager(options ...Option) *Manager {
+	return &Manager{
+		options: options,
+		files:   make(map[string]*managedFile),
+		sinks:   make(map[string]*managedSink),
+	}
+}
+
+// NewManagerFromConfig creates a Manager tailing the files of cfg
+//
+// Sinks referred to by cfg must be passed along, as they cannot be configured declaratively.
+func NewManagerFromConfig(cfg ManagerConfig, sinks map[string]Sink, options ...Option) (*Manager, error) {
+	m := NewManager(options...)
+
+	for name, sink := range sinks {
+		err := m.AddSink(name, sink)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := m.Apply(cfg)
+	if err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// AddSink adds a sink the records of the files are written to
+//
+// Files are assigned to sinks by name (see ManagerFile.Sinks); files without
+// assigned sinks, like those added by Add, are written to all sinks.
+//
+// While running, the Manager saves the checkpoint of a file (if its reader
+// has a CheckpointStore) after every batch of records: once the sinks with
+// AtLeastOnce delivery have acknowledged it, and before it's delivered to the
+// sinks with AtMostOnce delivery (see WithDeliveryMode).
+func (m *Manager) AddSink(name string, sink Sink, options ...SinkOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	if _, ok := m.sinks[name]; ok {
+		return fmt.Errorf("sink %s: already added", name)
+	}
+
+	s := &managedSink{name: name, sink: sink}
+	for _, option := range options {
+		option(s)
+	}
+	m.sinks[name] = s
+	if m.runCtx != nil {
+		return m.startDrain(s)
+	}
+	return nil
+}
+
+// Add starts tailing path and returns its reader
+func (m *Manager) Add(path string, options ...Option) (*TailingReader, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.add(path, options, nil)
+}
+
+func (m *Manager) add(path string, options []Option, sinks []string) (*TailingReader, error) {
+	if m.closed {
+		return nil, ErrClosed
+	}
+	if _, ok := m.files[path]; ok {
+		return nil, fmt.Errorf("%s: already tailed", path)
+	}
+
+	tr, err := NewTailingReader(path, append(slices.Clip(options), m.options...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &managedFile{tr: tr, sinks: sinks}
+	m.files[path] = f
+	if m.runCtx != nil {
+		m.startPump(f)
+	}
+	return tr, nil
+}
+
+// Remove stops tailing path and closes its reader
+func (m *Manager) Remove(path string) error {
+	m.mu.Lock()
+	f, ok := m.files[path]
+	delete(m.files, path)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return f.tr.Close()
+}
+
+// Reader returns the reader of path
+func (m *Manager) Reader(path string) (*TailingReader, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[path]
+	if !ok {
+		return nil, false
+	}
+	return f.tr, true
+}
+
+// Readers returns the readers of all tailed files by path
+func (m *Manager) Readers() map[string]*TailingReader {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	readers := make(map[string]*TailingReader, len(m.files))
+	for path, f := range m.files {
+		readers[path] = f.tr
+	}
+	return readers
+}
+
+// Apply starts, updates and stops readers to match cfg
+//
+// Glob patterns are expanded to the currently matching files. Errors of
+// individual files are joined; the other files are applied regardless.
+func (m *Manager) Apply(cfg ManagerConfig) error {
+	files, err := cfg.FileOptions()
+	if err != nil {
+		return err
+	}
+
+	decoders := make([]patternDecoder, 0, len(cfg.Decoders))
+	for _, d := range cfg.Decoders {
+		decoder, err := NewDecoder(d.Decoder)
+		if err == nil {
+			_, err = filepath.Match(d.Pattern, "")
+		}
+		if err != nil {
+			return fmt.Errorf("decoder of %s: %w", d.Pattern, err)
+		}
+		decoders = append(decoders, patternDecoder{pattern: d.Pattern, decoder: decoder})
+	}
+
+	sinks := make(map[string][]string, len(cfg.Files))
+	for _, f := range cfg.Files {
+		sinks[f.Path] = f.Sinks
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	patterns, err := expandPaths(paths)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	m.cfgDecoders = decoders
+
+	var errs []error
+	for path, f := range m.files {
+		if _, ok := patterns[path]; !ok {
+			delete(m.files, path)
+			errs = append(errs, f.tr.Close())
+		}
+	}
+
+	for path, pattern := range patterns {
+		err = m.apply(path, files[pattern], sinks[pattern])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// apply starts or updates tailing path with the given options and sinks
+func (m *Manager) apply(path string, options []Option, sinks []string) error {
+	for _, name := range sinks {
+		if _, ok := m.sinks[name]; !ok {
+			return fmt.Errorf("unknown sink %q", name)
+		}
+	}
+
+	f, ok := m.files[path]
+	if !ok {
+		_, err := m.add(path, options, sinks)
+		return err
+	}
+
+	f.sinks = sinks
+	return f.tr.UpdateOptions(append(slices.Clip(options), m.options...)...)
+}
+
+// AddDecoder sets the decoder of the files matching pattern, e.g. DockerDecoder for "*-json.log"
+//
+// Patterns without a directory match the base names of files, others their
+// paths (see filepath.Match). Decoders added first take precedence, and
+// decoders of a ManagerConfig (see ManagerConfig.Decoders) apply to files
+// matching none; other files are read as lines. The decoder of a file is
+// chosen once its reading starts.
+func (m *Manager) AddDecoder(pattern string, decoder Decoder) error {
+	_, err := filepath.Match(pattern, "")
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+
+	m.decoders = append(m.decoders, patternDecoder{pattern: pattern, decoder: decoder})
+	return nil
+}
+
+// decoderOf returns the decoder of the file at path
+func (m *Manager) decoderOf(path string) Decoder {
+	for _, d := range append(slices.Clip(m.decoders), m.cfgDecoders...) {
+		if d.matches(path) {
+			return d.decoder
+		}
+	}
+	return LineDecoder{}
+}
+
+// UseRecordOptions sets the options the records of all files are read with, e.g. WithTimestampExtractor
+//
+// It must be called before Run.
+func (m *Manager) UseRecordOptions(options ...RecordOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	if m.runCtx != nil {
+		return errManagerRunning
+	}
+
+	m.recordOptions = options
+	return nil
+}
+
+// Run writes the records of all files to their sinks until ctx is cancelled or a sink fails
+// (after its retries, see WithSinkRetries)
+//
+// Files added while running are included. Reading a file ends once its reader
+// returns io.EOF (e.g. with TimeoutsAsEOF) or is removed; after ErrIdleTimeout
+// the file is read on. Run owns the Manager and closes it before returning; it
+// returns ctx.Err() if ctx was cancelled, nil if the Manager was closed, and
+// the error of a sink or reader otherwise.
+// With a write-ahead log (see UseWAL), sinks don't fail a run, but errors of the log do.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	if m.runCtx != nil {
+		m.mu.Unlock()
+		return errManagerRunning
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	m.runCtx, m.runCancel = runCtx, cancel
+	for _, sink := range m.sinks {
+		err := m.startDrain(sink)
+		if err != nil {
+			cancel(err)
+			break
+		}
+	}
+	for _, f := range m.files {
+		m.startPump(f)
+	}
+	m.mu.Unlock()
+
+	<-runCtx.Done()
+	err := context.Cause(runCtx)
+
+	closeErr := m.Close()
+	m.pumps.Wait()
+	m.drains.Wait()
+	closeErr = errors.Join(closeErr, m.closeWALs())
+
+	if errors.Is(err, errManagerClosed) {
+		err = nil
+	}
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// startPump starts writing the records of f to its sinks
+func (m *Manager) startPump(f *managedFile) {
+	ctx, cancel := m.runCtx, m.runCancel
+
+	m.pumps.Add(1)
+	go func() {
+		defer m.pumps.Done()
+
+		err := m.pump(ctx, f)
+		if err != nil && ctx.Err() == nil && !errors.Is(err, ErrClosed) {
+			cancel(err)
+		}
+	}()
+}
+
+// pump writes the records read from f to its sinks in batches until reading ends
+func (m *Manager) pump(ctx context.Context, f *managedFile) error {
+	m.mu.Lock()
+	decoder := m.decoderOf(f.tr.FilePath())
+	rr := NewRecordReader(f.tr, append(slices.Clip(m.recordOptions), decoder.RecordOptions()...)...)
+	m.mu.Unlock()
+	for {
+		batch, err := rr.ReadRecordBatch(managerBatchSize, managerBatchWait)
+		if len(batch) > 0 {
+			batch = decodeBatch(decoder, batch)
+			sinks := m.sinksOf(f)
+			sinkErr := m.deliver(ctx, sinks, AtLeastOnce, batch)
+			if sinkErr != nil {
+				return sinkErr
+			}
+
+			cpErr := f.tr.SaveCheckpointAt(rr.endOffset())
+			if cpErr != nil && !errors.Is(cpErr, ErrNoCheckpointStore) {
+				return fmt.Errorf("%s: %w", f.tr.FilePath(), cpErr)
+			}
+
+			sinkErr = m.deliver(ctx, sinks, AtMostOnce, batch)
+			if sinkErr != nil {
+				return sinkErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if errors.Is(err, ErrIdleTimeout) {
+			// an idle file neither ends the run nor reading it
+			continue
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", f.tr.FilePath(), err)
+		}
+	}
+}
+
+// deliver writes batch to the sinks with the given delivery mode, or appends it to their write-ahead logs
+func (m *Manager) deliver(ctx context.Context, sinks []*managedSink, mode DeliveryMode, batch []Record) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	for _, sink := range sinks {
+		if sink.mode != mode {
+			continue
+		}
+
+		var err error
+		if sink.wal != nil {
+			err = sink.wal.append(ctx, batch)
+		} else {
+			err = sink.deliver(ctx, batch, sink.retries)
+		}
+		if err != nil {
+			return fmt.Errorf("sink %s: %w", sink.name, err)
+		}
+	}
+	return nil
+}
+
+// sinksOf returns the sinks f is written to
+func (m *Manager) sinksOf(f *managedFile) []*managedSink {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(f.sinks) == 0 {
+		sinks := make([]*managedSink, 0, len(m.sinks))
+		for _, sink := range m.sinks {
+			sinks = append(sinks, sink)
+		}
+		return sinks
+	}
+
+	sinks := make([]*managedSink, 0, len(f.sinks))
+	for _, name := range f.sinks {
+		if sink, ok := m.sinks[name]; ok {
+			sinks = append(sinks, sink)
+		}
+	}
+	return sinks
+}
+
+// Close stops tailing all files
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	files := m.files
+	m.files = make(map[string]*managedFile)
+	m.closed = true
+	if m.runCancel != nil {
+		m.runCancel(errManagerClosed)
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, f := range files {
+		errs = append(errs, f.tr.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// expandPaths maps the files matching glob patterns (and paths without wildcards) to their pattern
+func expandPaths(patterns []string) (map[string]string, error) {
+	paths := make(map[string]string, len(patterns))
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			paths[pattern] = pattern
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			paths[match] = pattern
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package tailreader
+
+import "fmt"
+
+var errMmapUnsupported = fmt.Errorf("memory mapping is not supported on this platform")
+
+func (r *TailingReader) mapFile(size int64) error {
+	return errMmapUnsupported
+}
+
+func (r *TailingReader) unmapFile() error {
+	return nil
+}
+
+func (r *TailingReader) readMmap(p []byte) (int, error) {
+	return 0, errMmapUnsupported
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package tailreader
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"golang.org/x/sys/unix"
+)
+
+var errMmapFault = fmt.Errorf("fault while reading memory mapped file")
+
+// mapFile maps the file from the current offset (rounded down to page size) up to size
+func (r *TailingReader) mapFile(size int64) error {
+	offset := r.offset &^ int64(os.Getpagesize()-1)
+
+	data, err := unix.Mmap(int(r.osFile.Fd()), offset, int(size-offset), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+
+	r.mmap = data
+	r.mmapOffset = offset
+
+	return nil
+}
+
+func (r *TailingReader) unmapFile() error {
+	if r.mmap == nil {
+		return nil
+	}
+
+	err := unix.Munmap(r.mmap)
+	r.mmap = nil
+	r.mmapOffset = 0
+
+	return err
+}
+
+func (r *TailingReader) readMmap(p []byte) (n int, err error) {
+	// accessing pages beyond the end of a file that was truncated in the
+	// meantime raises SIGBUS; let the runtime turn that into a recoverable panic
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if recover() != nil {
+			n, err = 0, errMmapFault
+		}
+	}()
+
+	return copy(p, r.mmap[r.offset-r.mmapOffset:]), nil
+}
@@ -0,0 +1,56 @@
+package tailreader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// consumedSize is the amount of consumed data verified by DetectModification
+const consumedSize = 4096
+
+var ErrModified = fmt.Errorf("already consumed data was modified")
+
+// remember keeps the end of the data read from the file
+func (r *TailingReader) remember(data []byte) {
+	if !r.options.DetectModification {
+		return
+	}
+
+	r.consumed = append(r.consumed, data...)
+	if len(r.consumed) > consumedSize {
+		r.consumed = append(r.consumed[:0], r.consumed[len(r.consumed)-consumedSize:]...)
+	}
+}
+
+// verifyConsumed compares the data remembered with the file's current content
+//
+// Files that don't support ReadAt (or are opened for direct I/O) aren't verified.
+func (r *TailingReader) verifyConsumed() error {
+	if len(r.consumed) == 0 || r.file == nil || r.direct {
+		return nil
+	}
+
+	readerAt, ok := r.file.(io.ReaderAt)
+	if !ok {
+		return nil
+	}
+
+	start := r.offset - int64(len(r.consumed))
+	buf := make([]byte, len(r.consumed))
+	n, err := readerAt.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n < len(buf) {
+		// truncated, which is handled separately
+		return nil
+	}
+
+	if !bytes.Equal(buf, r.consumed) {
+		r.consumed = append(r.consumed[:0], buf...)
+		return fmt.Errorf("%w: %s between offsets %d and %d", ErrModified, r.filePath, start, r.offset)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package tailreader
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// nfsSizeTolerance is how long the file may appear smaller than the offset before
+// it's considered truncated in NFS mode; it's the default minimum time NFS clients
+// cache file attributes (acregmin)
+const nfsSizeTolerance = 3 * time.Second
+
+// tolerateShrink reports whether the file appearing smaller (size) than the offset should be ignored for now
+//
+// On NFS, the size reported by stat may lag behind the data that was already read
+// because of attribute caching, so the file is only considered truncated once it
+// stays smaller for nfsSizeTolerance.
+func (r *TailingReader) tolerateShrink(size int64) bool {
+	if !r.options.NFS {
+		return false
+	}
+	if r.offset <= size {
+		r.shrunkSince = time.Time{}
+		return false
+	}
+
+	now := r.now()
+	if r.shrunkSince.IsZero() {
+		r.shrunkSince = now
+	}
+	if now.Sub(r.shrunkSince) < nfsSizeTolerance {
+		return true
+	}
+
+	r.shrunkSince = time.Time{}
+	return false
+}
+
+// refreshDir opens and closes the file's directory in NFS mode, which makes the
+// client revalidate its cached attributes and directory entries
+func (r *TailingReader) refreshDir() {
+	if !r.options.NFS || r.options.FS != nil {
+		return
+	}
+
+	dir, err := os.Open(longPath(filepath.Dir(r.filePath)))
+	if err == nil {
+		_ = dir.Close()
+	}
+}
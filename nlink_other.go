@@ -0,0 +1,7 @@
+//go:build !unix
+
+package tailreader
+
+func (r *TailingReader) unlinked() bool {
+	return false
+}
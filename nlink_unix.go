@@ -0,0 +1,19 @@
+//go:build unix
+
+package tailreader
+
+import "syscall"
+
+// unlinked checks whether the link count of the open file dropped to zero, see DetectUnlink
+func (r *TailingReader) unlinked() bool {
+	if !r.options.DetectUnlink || r.osFile == nil {
+		return false
+	}
+
+	info, err := r.osFile.Stat()
+	if err != nil {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Nlink == 0
+}
@@ -0,0 +1,44 @@
+package tailreader
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/sys/unix"
+)
+
+// readNoWait reads at the current offset without blocking on disk I/O
+//
+// It returns 0 bytes if the data is not available without blocking or if the
+// kernel does not support RWF_NOWAIT (in which case it won't be tried again).
+func (r *TailingReader) readNoWait(p []byte) (int, error) {
+	if r.noWaitUnsupported {
+		return 0, nil
+	}
+
+	if r.options.SkipHoles {
+		err := r.skipHole()
+		if err != nil {
+			// only a hole left up to the end of the file
+			return 0, nil
+		}
+	}
+
+	n, err := unix.Preadv2(int(r.osFile.Fd()), [][]byte{p}, r.offset, unix.RWF_NOWAIT)
+	if err != nil {
+		if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EINVAL) {
+			r.noWaitUnsupported = true
+		}
+		return 0, nil
+	}
+
+	if n > 0 {
+		// pread doesn't move the file position, keep it in sync for subsequent reads
+		_, err = r.osFile.Seek(r.offset+int64(n), io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package tailreader
+
+func (r *TailingReader) readNoWait(p []byte) (int, error) {
+	return 0, nil
+}
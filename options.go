@@ -1,6 +1,19 @@
 package tailreader
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var ErrInvalidOptions = fmt.Errorf("invalid options")
+
+const DefaultPollInterval = 250 * time.Millisecond
+
+// DefaultEventMask is the set of operations waking a Read if EventMask is not set
+// (fsnotify.Chmod is triggered on truncate)
+const DefaultEventMask = fsnotify.Write | fsnotify.Remove | fsnotify.Rename | fsnotify.Chmod
 
 type Options struct {
 	// WaitForFile indicates whether the reader should wait for the file to be created
@@ -14,22 +27,336 @@ type Options struct {
 	// If this is set to 0, the reader will wait indefinitely.
 	WaitForFileTimeout time.Duration
 
+	// WaitForRegularFile indicates whether the reader should wait while the path is a directory
+	// If this is set to false, Read returns ErrNotAFile. Otherwise it waits (like for a missing
+	// file, see WaitForFileTimeout) until the directory is replaced by a file again.
+	WaitForRegularFile bool
+
 	// CloseOnDelete indicates whether the reader should be closed if the file is deleted
 	CloseOnDelete bool
 
+	// DetectUnlink indicates whether the open file's link count dropping to zero should be treated as deletion
+	// This catches deletions without a Remove event for the file's path, e.g. through another
+	// hard link. The link count is checked every PollInterval while waiting. Only supported on Unix.
+	DetectUnlink bool
+
 	// CloseOnTruncate indicates whether the reader should be closed if the file is truncated
 	CloseOnTruncate bool
 
-	// IdleTimeout indicates how long the reader should wait for new data before closing
+	// SeekOnTruncate indicates whether the open file should be read again from offset 0 after truncation
+	// By default, a truncated file is closed and reopened, which loses the descriptor and races
+	// with a writer replacing the file meanwhile. Only applies if CloseOnTruncate is false.
+	SeekOnTruncate bool
+
+	// OnTruncate is called with the offset the reader was at when truncation of the file is detected
+	// It's called by Read while the reader is locked, so it must not call the reader's methods.
+	OnTruncate func(offset int64)
+
+	// IdleTimeout indicates how long the reader should wait for new data before Read returns ErrIdleTimeout
+	// The reader isn't closed; reading again waits for another IdleTimeout.
 	// If this is set to 0, the reader will wait indefinitely
 	IdleTimeout time.Duration
 
 	// Whether or not .Read() should return io.EOF if the wait for file or idle timeout is reached
 	TreatTimeoutsAsEOF bool
+
+	// OnIdleTimeout decides what Read does once IdleTimeout is reached, overriding TreatTimeoutsAsEOF
+	// It's called with how long the reader has been waiting for data, which keeps growing as
+	// long as it returns IdleContinue, so adaptive policies (e.g. keep waiting during known
+	// quiet hours) don't need to wrap Read in retry loops. It's called by Read while the
+	// reader is locked, so it must not call the reader's methods.
+	OnIdleTimeout func(idle time.Duration) IdleAction
+
+	// MmapThreshold enables memory mapped reads while catching up on existing data
+	// If at least this many bytes are pending, they are copied from a memory mapping of the
+	// file instead of being read with many small read calls; live tailing always uses normal reads.
+	// If this is set to 0, memory mapping is disabled.
+	MmapThreshold int64
+
+	// Fadvise indicates whether the reader should give page cache hints to the kernel (Linux only)
+	// The file is advised to be read sequentially and pages behind the consumed offset are
+	// dropped from the page cache, so tailing huge files doesn't evict other cached data.
+	Fadvise bool
+
+	// NoWaitRead indicates whether the reader should try a non-blocking read before waiting for changes (Linux only)
+	// This uses preadv2(RWF_NOWAIT) to pick up data that is already in the page cache without
+	// waiting for a file system notification, which reduces latency under load. It isn't
+	// used with DirectIO or a StabilityWindow.
+	NoWaitRead bool
+
+	// DirectIO indicates whether the file should be opened with O_DIRECT (Linux only)
+	// Reads bypass the page cache entirely, which is useful when tailing huge files that
+	// must not pollute the page cache. Alignment requirements are handled internally.
+	DirectIO bool
+
+	// SkipHoles indicates whether holes in sparse files should be skipped (Linux, macOS and FreeBSD only)
+	// Instead of delivering zero-filled regions, reading continues at the next data region (SEEK_DATA).
+	SkipHoles bool
+
+	// Readahead is the minimum number of bytes the reader tries to read from the file at once
+	// If the buffer passed to Read is smaller, data is read into an internal buffer of this size
+	// and returned by subsequent reads, which reduces syscalls for consumers using small buffers.
+	// If this is set to 0, reads go directly into the caller's buffer.
+	Readahead int
+
+	// Prefetch indicates whether the file should be read in the background
+	// A goroutine started by the first Read waits for changes and reads up to
+	// BufferSize bytes ahead, so Read merely returns buffered data and
+	// consumer latency is decoupled from event handling. Errors are returned by
+	// Read after the data read before them.
+	Prefetch bool
+
+	// BufferSize is the size of the ring buffer for data read in the background (see Prefetch)
+	// It is the amount of slack a slow consumer has before reading from the file pauses.
+	// If this is set to 0, DefaultBufferSize is used.
+	BufferSize int
+
+	// SpillDir is the directory of a temporary file for prefetched data exceeding BufferSize
+	// Instead of pausing once the memory buffer is full, reading in the background goes
+	// on into the file, e.g. to preserve data that would be lost when a stalled consumer
+	// falls behind log rotation. The file is removed on Close. Use os.TempDir() for the
+	// default temporary directory. If this is empty, data isn't spilled to disk.
+	SpillDir string
+
+	// MaxSpillSize is the maximum number of bytes kept in the spill file
+	// Once it's reached, reading in the background pauses. If this is set to 0, the
+	// spill file is not limited.
+	MaxSpillSize int64
+
+	// StabilityWindow is how long the file's size must be unchanged before appended data is read
+	// Writers updating a record in multiple small writes would otherwise have their torn
+	// records delivered. Data ending with a newline is read right away. If this is set to
+	// 0, data is read as soon as it's noticed.
+	StabilityWindow time.Duration
+
+	// GrowthLimit is the number of bytes per second the file may grow by for longer than GrowthLimitPeriod
+	// This protects downstream systems from a misbehaving producer flooding the file: the
+	// excess is reported to OnGrowthLimit, or Read returns an error wrapping ErrGrowthLimit
+	// if that's not set. It's reported again only after the growth rate fell below the
+	// limit meanwhile. If this is set to 0, the growth rate is not checked.
+	GrowthLimit       int64
+	GrowthLimitPeriod time.Duration
+
+	// OnGrowthLimit is called with the growth rate (in bytes per second) when GrowthLimit is exceeded
+	// It's called by Read while the reader is locked, so it must not call the reader's methods.
+	OnGrowthLimit func(rate int64)
+
+	// StallTimeout is how long no data may be read before OnStall is called
+	// Unlike IdleTimeout, it doesn't end a pending Read: the reader continues waiting, and
+	// OnStall is called again once data arrives, so monitoring can alert on silent producers
+	// without terminating the tail. If this is set to 0, stalls are not detected.
+	StallTimeout time.Duration
+
+	// OnStall is called with true when the reader stalls and with false when it recovers
+	// It's called by Read while the reader is locked, so it must not call the reader's methods.
+	OnStall func(stalled bool)
+
+	// RateLimit is the maximum number of bytes per second delivered by Read
+	// A token bucket allowing bursts of up to one second's worth of data is used, so catching
+	// up on a huge backlog doesn't saturate the disk or downstream sinks. Waiting for changes
+	// is not affected. If this is set to 0, reads are not limited.
+	RateLimit int64
+
+	// CatchUpRateLimit and CatchUpReadahead replace RateLimit and Readahead until the reader has caught up
+	// Replaying a large backlog can be throttled harder (or read with larger buffers) than
+	// live tailing, so it doesn't starve other readers of the same process. See CaughtUp
+	// for when the catch-up phase ends. If these are set to 0, the live settings are used.
+	CatchUpRateLimit int64
+	CatchUpReadahead int
+
+	// Checksum is the algorithm of a running checksum of all data delivered by Read
+	// The checksum is reported by Stats and saved in checkpoints, so downstream systems can
+	// verify integrity and detect divergence after resuming. If this is empty, no checksum is kept.
+	Checksum Checksum
+
+	// DetectModification indicates whether the reader should detect rewrites of already consumed data
+	// Whenever the file changes, the last up to 4 KiB read are compared with the file's current
+	// content, and Read returns an error wrapping ErrModified if they differ instead of silently
+	// delivering an inconsistent stream. Reading continues at the current offset afterwards.
+	DetectModification bool
+
+	// IgnoreChmod indicates whether metadata changes should be ignored while waiting for data
+	// Some platforms generate frequent Chmod events that wake the reader although no data
+	// changed. As truncation is only signaled by a Chmod event, each one is still checked
+	// by comparing the file's size with the current offset.
+	IgnoreChmod bool
+
+	// EventMask is the set of file system operations that wake a Read waiting for new data
+	// Removing operations from the mask trades detection latency for fewer wake-ups on file
+	// systems with unusual notification behavior; e.g. without fsnotify.Chmod, truncation is
+	// only noticed with the next write. Waiting for the file to be created always uses
+	// fsnotify.Create. If this is set to 0, DefaultEventMask is used.
+	EventMask fsnotify.Op
+
+	// FollowMode determines whether the reader follows the path or the open file after it was renamed or deleted
+	// If this is empty, FollowName is used.
+	FollowMode FollowMode
+
+	// FallbackPaths are tailed if the file doesn't exist, in order of preference
+	// Whenever the current file is missing, the reader switches to the first existing path
+	// among the file passed to the constructor and FallbackPaths (starting at offset 0),
+	// e.g. for applications writing to either /var/log/app.log or ./app.log. If none of
+	// them exists, the reader waits (or fails) as configured for a single file.
+	FallbackPaths []string
+
+	// Glob indicates whether the path passed to the constructor and FallbackPaths are glob patterns
+	// While no matching file exists, the reader waits (see WaitForFile) for the first one
+	// to be created; if multiple files match, the first in lexical order is tailed. Once
+	// the current file is gone, the reader switches to the next matching file. Patterns
+	// are matched by filepath.Match and may only contain wildcards in the file name.
+	Glob bool
+
+	// CaseInsensitive indicates whether event names are matched against the path case-insensitively
+	// Names reported for files on case-insensitive file systems (e.g. Samba mounts) may differ
+	// in case from the configured path. On Windows and macOS, they are always matched
+	// case-insensitively.
+	CaseInsensitive bool
+
+	// ResolveSymlinks indicates whether symbolic links are resolved before matching event names against the path
+	// Only the directories are resolved, not the file itself. This is needed if the file's
+	// directory is reached through different symlinked paths, e.g. if FallbackPaths refer
+	// to a symlink of the directory of the primary path.
+	ResolveSymlinks bool
+
+	// WatchFile indicates whether the file itself should be watched instead of its directory
+	// The directory is only watched while waiting for the file to be created. On macOS and
+	// BSDs, kqueue then watches the open file only, rather than every file of a directory,
+	// which is cheaper and delivers writes with less latency in busy directories.
+	// This is the only macOS specific tuning: fsnotify always uses kqueue there, so
+	// neither FSEvents nor an event latency can be configured.
+	WatchFile bool
+
+	// WatchBufferSize is the size of the buffer for file system notifications in bytes (Windows only)
+	// The default of 64 KiB may overflow in directories with very high churn; lost events
+	// only cause the file to be checked again, but a larger buffer avoids the extra work.
+	// If this is set to 0, the default is used.
+	WatchBufferSize int
+
+	// NFS indicates whether the file is on a remote file system like NFS or CIFS
+	// File system notifications don't fire for changes made by other clients, so the file
+	// is polled (see PollInterval) and its directory is reopened on every poll to defeat
+	// attribute caching. As cached sizes may lag behind, the file is only considered
+	// truncated once it stayed smaller than the offset for a few seconds, and appended
+	// data that isn't visible yet is read again on the next poll.
+	NFS bool
+
+	// PollInterval is the interval at which the file is checked for changes when polling
+	// Polling is used if there are no file system notifications, e.g. for fs.FS backends.
+	// If this is set to 0, DefaultPollInterval is used.
+	PollInterval time.Duration
+
+	// StartAtEnd indicates whether the reader should skip the data that exists when it is created
+	// If the file doesn't exist yet, reading starts at the beginning once it is created.
+	StartAtEnd bool
+
+	// StartAtPattern makes the reader skip the existing data up to the first occurrence of this byte sequence
+	// This is useful for binary formats with marked segment boundaries, e.g. a session marker
+	// or magic header. If the existing data doesn't contain the pattern, reading starts at
+	// its end; if the file doesn't exist yet, reading starts at the beginning once it's created.
+	StartAtPattern []byte
+
+	// CheckpointStore is used to resume reading at the offset saved by SaveCheckpoint
+	// If there's a checkpoint for the file, it takes precedence over StartAtEnd.
+	CheckpointStore CheckpointStore
+
+	// LockFile is the path of a lock file ensuring that only one reader actively tails the file
+	// When multiple replicas of a collector run against shared storage, the reader holding
+	// an exclusive advisory lock on this file reads while the others wait in Read until the
+	// lock is released (checked every PollInterval). The lock is released on Close.
+	// With a shared CheckpointStore, a reader acquiring the lock resumes at the offset
+	// committed by the previous holder, so a standby takes over where the active reader stopped.
+	LockFile string
+
+	// RespectWriterLock indicates whether reading waits while a writer holds an exclusive advisory lock on the file
+	// For producers locking the file (flock(2) on Unix, LockFileEx on Windows) while writing
+	// a record, new data is only read under a shared lock, so partially written records
+	// aren't delivered. The lock is checked every PollInterval while it's held by a writer.
+	RespectWriterLock bool
+
+	// StatsInterval is the interval at which OnStats is called with the reader's statistics
+	// The statistics include the bytes and lines delivered since the previous call, so
+	// throughput can be logged or exported without sampling Stats. Changing either field
+	// with UpdateOptions has no effect.
+	StatsInterval time.Duration
+
+	// OnStats is called every StatsInterval from a separate goroutine until the reader is closed
+	OnStats func(stats Stats)
+
+	// Labels are static labels (e.g. service and environment) attached to every record
+	// Records read by a RecordReader carry them in Record.Labels, so sinks can route and
+	// index records without deriving that context from the path.
+	Labels map[string]string
+
+	// FS is the file system used to access the file instead of the operating system's
+	// As there are no file system notifications for custom file systems, changes are
+	// detected by polling (see PollInterval).
+	FS FS
+
+	// Clock is the source of time for timeouts and polling
+	// If this is nil, the system clock is used.
+	Clock Clock
+
+	// StatFunc overrides the function used to stat the file
+	// This allows injecting failures (e.g. ENOENT races or stale sizes) in tests.
+	StatFunc StatFunc
+
+	// OpenFunc overrides the function used to open the file
+	// It is called with the read-only flags the reader would use (e.g. including O_DIRECT),
+	// so callers can add flags like O_NOFOLLOW or O_NONBLOCK, use Windows share modes or
+	// open the file relative to a capability-restricted directory. Files returned as
+	// *os.File support all features of the operating system's files (e.g. WithMmap).
+	OpenFunc OpenFunc
 }
 
 type Option func(opts *Options)
 
+func (opts *Options) validate() error {
+	switch {
+	case opts.WaitForFileTimeout < 0:
+		return fmt.Errorf("%w: negative wait for file timeout", ErrInvalidOptions)
+	case opts.IdleTimeout < 0:
+		return fmt.Errorf("%w: negative idle timeout", ErrInvalidOptions)
+	case opts.MmapThreshold < 0:
+		return fmt.Errorf("%w: negative mmap threshold", ErrInvalidOptions)
+	case opts.Readahead < 0:
+		return fmt.Errorf("%w: negative readahead", ErrInvalidOptions)
+	case opts.BufferSize < 0:
+		return fmt.Errorf("%w: negative buffer size", ErrInvalidOptions)
+	case opts.MaxSpillSize < 0:
+		return fmt.Errorf("%w: negative max spill size", ErrInvalidOptions)
+	case opts.StabilityWindow < 0:
+		return fmt.Errorf("%w: negative stability window", ErrInvalidOptions)
+	case opts.GrowthLimit < 0:
+		return fmt.Errorf("%w: negative growth limit", ErrInvalidOptions)
+	case opts.GrowthLimitPeriod < 0:
+		return fmt.Errorf("%w: negative growth limit period", ErrInvalidOptions)
+	case opts.StallTimeout < 0:
+		return fmt.Errorf("%w: negative stall timeout", ErrInvalidOptions)
+	case opts.RateLimit < 0:
+		return fmt.Errorf("%w: negative rate limit", ErrInvalidOptions)
+	case opts.CatchUpRateLimit < 0:
+		return fmt.Errorf("%w: negative catch-up rate limit", ErrInvalidOptions)
+	case opts.CatchUpReadahead < 0:
+		return fmt.Errorf("%w: negative catch-up readahead", ErrInvalidOptions)
+	case opts.Checksum != "" && opts.Checksum.newHash() == nil:
+		return fmt.Errorf("%w: unknown checksum %q", ErrInvalidOptions, opts.Checksum)
+	case !opts.FollowMode.valid():
+		return fmt.Errorf("%w: unknown follow mode %q", ErrInvalidOptions, opts.FollowMode)
+	case opts.WatchBufferSize < 0:
+		return fmt.Errorf("%w: negative watch buffer size", ErrInvalidOptions)
+	case opts.StatsInterval < 0:
+		return fmt.Errorf("%w: negative stats interval", ErrInvalidOptions)
+	case opts.PollInterval < 0:
+		return fmt.Errorf("%w: negative poll interval", ErrInvalidOptions)
+	case !opts.WaitForFile && opts.WaitForFileTimeout > 0:
+		return fmt.Errorf("%w: wait for file timeout set without waiting for the file", ErrInvalidOptions)
+	case opts.WaitForFile && opts.WaitForFileTimeout == 0 && opts.CloseOnDelete:
+		// a file deleted before it was opened would be waited for forever instead of closing the reader
+		return fmt.Errorf("%w: close on delete requires a wait for file timeout", ErrInvalidOptions)
+	}
+	return nil
+}
+
 func WithWaitForFile(wait bool, timeout time.Duration) Option {
 	return func(opts *Options) {
 		opts.WaitForFile = wait
@@ -37,18 +364,42 @@ func WithWaitForFile(wait bool, timeout time.Duration) Option {
 	}
 }
 
+func WithWaitForRegularFile(wait bool) Option {
+	return func(opts *Options) {
+		opts.WaitForRegularFile = wait
+	}
+}
+
 func WithCloseOnDelete(close bool) Option {
 	return func(opts *Options) {
 		opts.CloseOnDelete = close
 	}
 }
 
+func WithDetectUnlink(detect bool) Option {
+	return func(opts *Options) {
+		opts.DetectUnlink = detect
+	}
+}
+
 func WithCloseOnTruncate(close bool) Option {
 	return func(opts *Options) {
 		opts.CloseOnTruncate = close
 	}
 }
 
+func WithSeekOnTruncate(seek bool) Option {
+	return func(opts *Options) {
+		opts.SeekOnTruncate = seek
+	}
+}
+
+func WithOnTruncate(onTruncate func(offset int64)) Option {
+	return func(opts *Options) {
+		opts.OnTruncate = onTruncate
+	}
+}
+
 func WithIdleTimeout(timeout time.Duration) Option {
 	return func(opts *Options) {
 		opts.IdleTimeout = timeout
@@ -60,3 +411,248 @@ func WithTimeoutsAsEOF(timeoutsAsEOF bool) Option {
 		opts.TreatTimeoutsAsEOF = timeoutsAsEOF
 	}
 }
+
+func WithOnIdleTimeout(onIdleTimeout func(idle time.Duration) IdleAction) Option {
+	return func(opts *Options) {
+		opts.OnIdleTimeout = onIdleTimeout
+	}
+}
+
+func WithMmap(threshold int64) Option {
+	return func(opts *Options) {
+		opts.MmapThreshold = threshold
+	}
+}
+
+func WithFadvise(fadvise bool) Option {
+	return func(opts *Options) {
+		opts.Fadvise = fadvise
+	}
+}
+
+func WithNoWaitRead(noWait bool) Option {
+	return func(opts *Options) {
+		opts.NoWaitRead = noWait
+	}
+}
+
+func WithDirectIO(direct bool) Option {
+	return func(opts *Options) {
+		opts.DirectIO = direct
+	}
+}
+
+func WithSkipHoles(skip bool) Option {
+	return func(opts *Options) {
+		opts.SkipHoles = skip
+	}
+}
+
+func WithReadahead(n int) Option {
+	return func(opts *Options) {
+		opts.Readahead = n
+	}
+}
+
+func WithPrefetch(prefetch bool) Option {
+	return func(opts *Options) {
+		opts.Prefetch = prefetch
+	}
+}
+
+func WithBufferSize(size int) Option {
+	return func(opts *Options) {
+		opts.BufferSize = size
+	}
+}
+
+func WithSpill(dir string, maxSize int64) Option {
+	return func(opts *Options) {
+		opts.SpillDir = dir
+		opts.MaxSpillSize = maxSize
+	}
+}
+
+func WithStabilityWindow(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.StabilityWindow = d
+	}
+}
+
+func WithGrowthLimit(rate int64, period time.Duration) Option {
+	return func(opts *Options) {
+		opts.GrowthLimit = rate
+		opts.GrowthLimitPeriod = period
+	}
+}
+
+func WithOnGrowthLimit(onGrowthLimit func(rate int64)) Option {
+	return func(opts *Options) {
+		opts.OnGrowthLimit = onGrowthLimit
+	}
+}
+
+func WithOnStall(timeout time.Duration, onStall func(stalled bool)) Option {
+	return func(opts *Options) {
+		opts.StallTimeout = timeout
+		opts.OnStall = onStall
+	}
+}
+
+func WithRateLimit(bytesPerSec int64) Option {
+	return func(opts *Options) {
+		opts.RateLimit = bytesPerSec
+	}
+}
+
+func WithCatchUp(bytesPerSec int64, readahead int) Option {
+	return func(opts *Options) {
+		opts.CatchUpRateLimit = bytesPerSec
+		opts.CatchUpReadahead = readahead
+	}
+}
+
+func WithChecksum(checksum Checksum) Option {
+	return func(opts *Options) {
+		opts.Checksum = checksum
+	}
+}
+
+func WithDetectModification(detect bool) Option {
+	return func(opts *Options) {
+		opts.DetectModification = detect
+	}
+}
+
+func WithIgnoreChmod(ignore bool) Option {
+	return func(opts *Options) {
+		opts.IgnoreChmod = ignore
+	}
+}
+
+func WithEventMask(mask fsnotify.Op) Option {
+	return func(opts *Options) {
+		opts.EventMask = mask
+	}
+}
+
+func WithFollowMode(mode FollowMode) Option {
+	return func(opts *Options) {
+		opts.FollowMode = mode
+	}
+}
+
+func WithFallbackPaths(paths ...string) Option {
+	return func(opts *Options) {
+		opts.FallbackPaths = paths
+	}
+}
+
+func WithGlob(glob bool) Option {
+	return func(opts *Options) {
+		opts.Glob = glob
+	}
+}
+
+func WithCaseInsensitive(caseInsensitive bool) Option {
+	return func(opts *Options) {
+		opts.CaseInsensitive = caseInsensitive
+	}
+}
+
+func WithResolveSymlinks(resolve bool) Option {
+	return func(opts *Options) {
+		opts.ResolveSymlinks = resolve
+	}
+}
+
+func WithWatchFile(watchFile bool) Option {
+	return func(opts *Options) {
+		opts.WatchFile = watchFile
+	}
+}
+
+func WithWatchBufferSize(size int) Option {
+	return func(opts *Options) {
+		opts.WatchBufferSize = size
+	}
+}
+
+func WithNFS(enabled bool) Option {
+	return func(opts *Options) {
+		opts.NFS = enabled
+	}
+}
+
+func WithPollInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.PollInterval = interval
+	}
+}
+
+func WithLockFile(path string) Option {
+	return func(opts *Options) {
+		opts.LockFile = path
+	}
+}
+
+func WithRespectWriterLock(respect bool) Option {
+	return func(opts *Options) {
+		opts.RespectWriterLock = respect
+	}
+}
+
+func WithFS(fsys FS) Option {
+	return func(opts *Options) {
+		opts.FS = fsys
+	}
+}
+
+func WithStatsInterval(interval time.Duration, onStats func(stats Stats)) Option {
+	return func(opts *Options) {
+		opts.StatsInterval = interval
+		opts.OnStats = onStats
+	}
+}
+
+func WithClock(clock Clock) Option {
+	return func(opts *Options) {
+		opts.Clock = clock
+	}
+}
+
+func WithStatFunc(stat StatFunc) Option {
+	return func(opts *Options) {
+		opts.StatFunc = stat
+	}
+}
+
+func WithOpenFunc(open OpenFunc) Option {
+	return func(opts *Options) {
+		opts.OpenFunc = open
+	}
+}
+
+func WithStartAtEnd(startAtEnd bool) Option {
+	return func(opts *Options) {
+		opts.StartAtEnd = startAtEnd
+	}
+}
+
+func WithStartAtPattern(pattern []byte) Option {
+	return func(opts *Options) {
+		opts.StartAtPattern = pattern
+	}
+}
+
+func WithCheckpointStore(store CheckpointStore) Option {
+	return func(opts *Options) {
+		opts.CheckpointStore = store
+	}
+}
+
+func WithLabels(labels map[string]string) Option {
+	return func(opts *Options) {
+		opts.Labels = labels
+	}
+}
@@ -0,0 +1,38 @@
+package tailreader
+
+import (
+	"bytes"
+	"io"
+)
+
+// findPattern returns the offset of the first occurrence of StartAtPattern in the existing content
+//
+// It returns false if the pattern doesn't occur (or the file doesn't exist).
+func (r *TailingReader) findPattern() (int64, bool, error) {
+	pattern := r.options.StartAtPattern
+
+	offset, found := int64(0), false
+	err := r.withReaderAt(func(f io.ReaderAt, size int64) error {
+		buf := make([]byte, max(64*1024, 2*len(pattern)))
+		for pos := int64(0); pos < size; {
+			n, err := f.ReadAt(buf, pos)
+			if err != nil && err != io.EOF {
+				return err
+			}
+
+			if i := bytes.Index(buf[:n], pattern); i >= 0 {
+				offset, found = pos+int64(i), true
+				return nil
+			}
+			if err == io.EOF || pos+int64(n) >= size {
+				return nil
+			}
+
+			// the pattern may span the end of the buffer
+			pos += int64(n - len(pattern) + 1)
+		}
+		return nil
+	})
+
+	return offset, found, err
+}
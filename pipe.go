@@ -0,0 +1,64 @@
+package tailreader
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// pipeBufferSize is the size of the buffer used to pump data into a pipe
+const pipeBufferSize = 32 * 1024
+
+// AsPipe returns a ReadCloser that is fed by a goroutine reading the tail
+//
+// The result can be handed to APIs that take ownership of a plain
+// io.ReadCloser (HTTP request bodies, decompressors). The pipe owns the
+// TailingReader: closing the pipe or cancelling ctx closes it. Errors
+// returned by Read are passed on to the pipe, io.EOF ends it normally and a
+// cancelled ctx ends it with ctx.Err().
+func (r *TailingReader) AsPipe(ctx context.Context) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = pw.CloseWithError(ctx.Err())
+		_ = r.Close()
+	})
+
+	go func() {
+		defer stop()
+
+		buf := make([]byte, pipeBufferSize)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				_, writeErr := pw.Write(buf[:n])
+				if writeErr != nil {
+					// the pipe was closed
+					_ = r.Close()
+					return
+				}
+			}
+
+			if err != nil {
+				if errors.Is(err, ErrClosed) && ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return &pipe{PipeReader: pr, tr: r}
+}
+
+// pipe closes the TailingReader along with the pipe
+type pipe struct {
+	*io.PipeReader
+	tr *TailingReader
+}
+
+func (p *pipe) Close() error {
+	_ = p.PipeReader.Close()
+	return p.tr.Close()
+}
@@ -0,0 +1,57 @@
+package tailreader
+
+import (
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// pollState is the last observed state of the file when polling for changes
+type pollState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+	version string
+}
+
+func (r *TailingReader) startPolling(fsys FS) {
+	r.setFS(fsys)
+	r.polling = true
+	r.pollFile()
+}
+
+func (r *TailingReader) pollInterval() time.Duration {
+	if r.options.PollInterval > 0 {
+		return r.options.PollInterval
+	}
+	return DefaultPollInterval
+}
+
+// pollFile stats the file and returns the operation that explains the
+// difference to the previous state, or 0 if the file didn't change
+func (r *TailingReader) pollFile() fsnotify.Op {
+	r.refreshDir()
+
+	var state pollState
+	info, err := r.statFile()
+	if err == nil {
+		state = pollState{exists: true, size: info.Size(), modTime: info.ModTime(), version: fileVersion(info)}
+	}
+
+	prev := r.pollState
+	r.pollState = state
+
+	switch {
+	case !state.exists && r.failoverAvailable():
+		// a fallback path can be tailed instead
+		return fsnotify.Create
+	case prev.exists && !state.exists:
+		return fsnotify.Remove
+	case !prev.exists && state.exists:
+		return fsnotify.Create
+	case state.exists && (state.size != prev.size || !state.modTime.Equal(prev.modTime) || state.version != prev.version):
+		return fsnotify.Write
+	}
+
+	return 0
+}
@@ -0,0 +1,147 @@
+package tailreader
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultBufferSize is the maximum amount of data read ahead in the background if BufferSize is not set
+const DefaultBufferSize = 1024 * 1024
+
+// prefetchChunkSize is the size of the reads performed in the background
+const prefetchChunkSize = 32 * 1024
+
+// startPrefetch starts reading in the background unless it's already running
+func (r *TailingReader) startPrefetch() {
+	if r.prefetchReady != nil {
+		return
+	}
+
+	r.prefetched = newRingBuffer(r.bufferSize())
+	r.prefetchReady = make(chan struct{}, 1)
+	r.prefetchFreed = make(chan struct{}, 1)
+	go r.prefetch()
+}
+
+// prefetch reads data into the prefetched ring buffer until the reader is closed
+//
+// It owns waiting for events and reading the file, so Read merely drains
+// prefetched (and the spill file, see SpillDir). Reading pauses while there's
+// no room and after an error until Read has returned the error.
+func (r *TailingReader) prefetch() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf := make([]byte, max(prefetchChunkSize, r.options.Readahead, r.options.CatchUpReadahead))
+	for !r.isClosed() {
+		room := r.prefetchRoom()
+		if room == 0 || r.prefetchErr != nil {
+			r.waitForRoom()
+			continue
+		}
+
+		if len(r.pending) > 0 {
+			// read ahead by fetch if the room was smaller than Readahead
+			n := min(len(r.pending), room)
+			r.prefetchErr = r.storePrefetched(r.pending[:n])
+			r.pending = r.pending[n:]
+		} else {
+			n, err := r.fetch(buf[:min(len(buf), room)], time.Time{})
+			if errors.Is(err, ErrClosed) {
+				return
+			}
+			r.prefetchErr = err
+			if n > 0 {
+				if storeErr := r.storePrefetched(buf[:n]); storeErr != nil {
+					r.prefetchErr = storeErr
+				}
+			}
+		}
+
+		select {
+		case r.prefetchReady <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// waitForRoom waits with the lock released until Read took data, the state changed or the reader was closed
+func (r *TailingReader) waitForRoom() {
+	r.mu.Unlock()
+	defer r.mu.Lock()
+
+	select {
+	case <-r.prefetchFreed:
+	case <-r.wakeup:
+	case <-r.closed:
+	}
+}
+
+// readPrefetched returns data (or an error) read in the background, waiting for it until deadline (unless it's zero)
+//
+// It must be called with r.mu held.
+func (r *TailingReader) readPrefetched(p []byte, deadline time.Time) (int, error) {
+	r.startPrefetch()
+
+	var c <-chan time.Time
+	if !deadline.IsZero() {
+		timer := r.clock().NewTimer(deadline.Sub(r.now()))
+		defer timer.Stop()
+		c = timer.C()
+	}
+
+	for {
+		if r.prefetched.Len() > 0 || r.spilled() > 0 || r.prefetchErr != nil {
+			n, err := r.takePrefetched(p)
+			if err != nil {
+				return n, err
+			}
+
+			err = r.prefetchErr
+			if n > 0 {
+				// data read before the error is returned first
+				err = nil
+			} else {
+				r.prefetchErr = nil
+			}
+
+			select {
+			case r.prefetchFreed <- struct{}{}:
+			default:
+			}
+			return n, err
+		}
+
+		r.mu.Unlock()
+		select {
+		case <-r.prefetchReady:
+		case <-c:
+			r.mu.Lock()
+			return 0, errTimeout
+		case <-r.closed:
+			r.mu.Lock()
+			return 0, ErrClosed
+		}
+		r.mu.Lock()
+	}
+}
+
+// discardPrefetched drops the data and error read in the background (and unread data), e.g. after seeking
+func (r *TailingReader) discardPrefetched() {
+	r.unreadData = nil
+	r.rehash = 0
+	if r.prefetched != nil {
+		r.prefetched.Reset()
+	}
+	if r.spill != nil {
+		_ = r.spill.Reset()
+	}
+	r.prefetchErr = nil
+}
+
+func (r *TailingReader) bufferSize() int {
+	if r.options.BufferSize > 0 {
+		return r.options.BufferSize
+	}
+	return DefaultBufferSize
+}
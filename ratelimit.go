@@ -0,0 +1,50 @@
+package tailreader
+
+import "time"
+
+// throttle waits until the rate limit allows delivering more data
+//
+// Tokens are refilled at limit bytes per second up to a burst of one
+// second's worth; delivered bytes may take the bucket below zero, in which
+// case the next read waits until the debt is paid off.
+func (r *TailingReader) throttle(bytesPerSec int64) error {
+	for {
+		limit := float64(bytesPerSec)
+		now := r.now()
+		if !r.rateStarted {
+			r.rateTokens = limit
+			r.rateStarted = true
+		} else {
+			r.rateTokens += now.Sub(r.rateTime).Seconds() * limit
+			if r.rateTokens > limit {
+				r.rateTokens = limit
+			}
+		}
+		r.rateTime = now
+
+		if r.rateTokens >= 0 {
+			return nil
+		}
+
+		err := r.sleep(time.Duration(-r.rateTokens / limit * float64(time.Second)))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d with the lock released, returning ErrClosed if the reader is closed meanwhile
+func (r *TailingReader) sleep(d time.Duration) error {
+	timer := r.clock().NewTimer(d)
+	defer timer.Stop()
+
+	r.mu.Unlock()
+	defer r.mu.Lock()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-r.closed:
+		return ErrClosed
+	}
+}
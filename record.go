@@ -0,0 +1,247 @@
+package tailreader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultMaxRecordSize is the maximum size of a record if none is set
+const DefaultMaxRecordSize = 1024 * 1024
+
+var errInvalidAdvance = fmt.Errorf("split function returned invalid advance count")
+
+// Record is a line read from a file
+type Record struct {
+	Path   string    // path of the file the record was read from
+	Offset int64     // file offset of the record's first byte
+	Data   []byte    // the line without the trailing newline
+	Time   time.Time // time the record was read
+
+	// Timestamp is the time of the event the record describes, as extracted by a
+	// TimestampExtractor, or the time the record was read if it has none
+	Timestamp time.Time
+
+	// Level is the severity of the record as extracted by a LevelExtractor, LevelNone if unknown
+	Level Level
+
+	Generation int64             // generation of the file the record was read from, see TailingReader.Generation
+	Labels     map[string]string // static labels of the file, see WithLabels
+}
+
+// RecordSource is anything records can be read from
+//
+// ReadRecord returns io.EOF once there are no more records.
+type RecordSource interface {
+	ReadRecord() (Record, error)
+}
+
+// RecordOption configures a RecordReader
+type RecordOption func(rr *RecordReader)
+
+// RecordReader assembles newline-terminated records from a TailingReader
+//
+// Lines longer than the maximum record size are split into multiple records.
+// An incomplete last line is returned as a record once the reader returns
+// io.EOF, or when the file was truncated (or seeked) in the middle of it.
+// After io.EOF or a timeout, ReadRecord can be called again to continue reading.
+type RecordReader struct {
+	tr      *TailingReader
+	buf     []byte
+	data    []byte // read but not yet returned data
+	offset  int64  // file offset of data[0]
+	gen     int64  // generation of the file data was read from
+	maxSize int
+	split   bufio.SplitFunc // splits data into records, nil for lines
+	err     error           // io.EOF to return once data is exhausted (once)
+
+	batchErr error // error to return by the next ReadRecordBatch
+
+	startAfter time.Time          // records up to this time are skipped, see WithStartAfterTime
+	parseTime  TimeParser         // extracts the timestamp of a record, nil if not starting at a time
+	extractor  TimestampExtractor // extracts Record.Timestamp, nil if not set
+
+	levelExtractor LevelExtractor // extracts Record.Level, nil if not set
+	skipping       bool           // whether records are skipped until one after startAfter
+	searched       bool           // whether the start time has been searched for
+}
+
+func NewRecordReader(tr *TailingReader, options ...RecordOption) *RecordReader {
+	rr := &RecordReader{
+		tr:      tr,
+		buf:     make([]byte, 32*1024),
+		maxSize: DefaultMaxRecordSize,
+	}
+
+	for _, option := range options {
+		option(rr)
+	}
+
+	return rr
+}
+
+// WithSplitFunc sets the function splitting the data into records instead of lines, e.g. for binary formats
+//
+// It's called like by a bufio.Scanner (atEOF is true when the reader returned
+// io.EOF); data it doesn't split into a record, even at EOF, is kept until
+// more is read. The maximum record size doesn't apply; the split function has
+// to limit the size of records.
+func WithSplitFunc(split bufio.SplitFunc) RecordOption {
+	return func(rr *RecordReader) {
+		rr.split = split
+	}
+}
+
+// WithMaxRecordSize sets the size at which lines are split into multiple records
+func WithMaxRecordSize(size int) RecordOption {
+	return func(rr *RecordReader) {
+		rr.maxSize = size
+	}
+}
+
+func (rr *RecordReader) ReadRecord() (Record, error) {
+	return rr.readRecord(time.Time{})
+}
+
+// readRecord reads like ReadRecord, but returns errTimeout if no record is complete by deadline (unless it's zero)
+func (rr *RecordReader) readRecord(deadline time.Time) (Record, error) {
+	if rr.parseTime != nil && !rr.searched {
+		rr.searched = true
+		err := rr.seekStartTime()
+		if err != nil {
+			return Record{}, err
+		}
+	}
+
+	for {
+		rec, err := rr.nextRecord(deadline)
+		if err != nil || !rr.skip(rec) {
+			return rec, err
+		}
+	}
+}
+
+// nextRecord returns the next record without skipping any
+func (rr *RecordReader) nextRecord(deadline time.Time) (Record, error) {
+	for {
+		if rr.split != nil {
+			rec, ok, err := rr.splitRecord()
+			if ok || err != nil {
+				return rec, err
+			}
+		} else if i := bytes.IndexByte(rr.data, '\n'); i >= 0 && i <= rr.maxSize {
+			return rr.emit(i, i+1), nil
+		} else if len(rr.data) >= rr.maxSize {
+			return rr.emit(rr.maxSize, rr.maxSize), nil
+		}
+
+		if rr.err != nil {
+			if len(rr.data) > 0 && rr.split == nil {
+				return rr.emit(len(rr.data), len(rr.data)), nil
+			}
+			// like the TailingReader, continue reading after io.EOF if called again
+			err := rr.err
+			rr.err = nil
+			return Record{}, err
+		}
+
+		n, offset, err := rr.tr.read(rr.buf, deadline)
+		if n > 0 {
+			gen := rr.tr.Generation()
+			if len(rr.data) > 0 && (offset != rr.offset+int64(len(rr.data)) || gen != rr.gen) {
+				// the file was truncated, rotated or seeked; the incomplete record ends here
+				var rec Record
+				if rr.split == nil {
+					rec = rr.emit(len(rr.data), len(rr.data))
+				} else {
+					// incomplete data can't be split, so it's dropped
+					rr.data = rr.data[:0]
+				}
+				rr.data = append(rr.data, rr.buf[:n]...)
+				rr.offset = offset
+				rr.gen = gen
+				if rr.split == nil {
+					return rec, nil
+				}
+				continue
+			}
+
+			if len(rr.data) == 0 {
+				rr.offset = offset
+				rr.gen = gen
+			}
+			rr.data = append(rr.data, rr.buf[:n]...)
+		}
+
+		if err == io.EOF {
+			rr.err = err
+		} else if err != nil {
+			return Record{}, err
+		}
+	}
+}
+
+// endOffset returns the file offset following the last record returned
+func (rr *RecordReader) endOffset() int64 {
+	return rr.offset
+}
+
+// splitRecord returns the next record split off the data by the split function, and whether there is one
+func (rr *RecordReader) splitRecord() (Record, bool, error) {
+	for len(rr.data) > 0 {
+		advance, token, err := rr.split(rr.data, rr.err != nil)
+		if err != nil {
+			return Record{}, false, err
+		}
+		if advance < 0 || advance > len(rr.data) || token != nil && advance == 0 {
+			return Record{}, false, errInvalidAdvance
+		}
+
+		if token != nil {
+			return rr.emitToken(token, advance), true, nil
+		} else if advance == 0 {
+			// more data is needed
+			break
+		}
+
+		rr.data = rr.data[advance:]
+		rr.offset += int64(advance)
+	}
+	return Record{}, false, nil
+}
+
+// emit returns the first n bytes of data as a record and skips them plus the separator
+func (rr *RecordReader) emit(n int, skip int) Record {
+	return rr.emitToken(rr.data[:n], skip)
+}
+
+// emitToken returns token (part of data) as a record and skips the first skip bytes of data
+func (rr *RecordReader) emitToken(token []byte, skip int) Record {
+	rec := Record{
+		Path:       rr.tr.FilePath(),
+		Offset:     rr.offset,
+		Data:       append([]byte(nil), token...),
+		Time:       rr.tr.now(),
+		Generation: rr.gen,
+		Labels:     rr.tr.labels(),
+	}
+	rec.Timestamp = rr.timestamp(rec.Data, rec.Time)
+	rec.Level = rr.level(rec.Data)
+
+	rr.data = rr.data[skip:]
+	rr.offset += int64(skip)
+
+	return rec
+}
+
+// labels returns the labels attached to records, see WithLabels
+//
+// UpdateOptions may replace them while reading, e.g. from the prefetch goroutine.
+func (r *TailingReader) labels() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.options.Labels
+}
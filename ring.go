@@ -0,0 +1,64 @@
+package tailreader
+
+// ringBuffer is a fixed size FIFO buffer of bytes
+type ringBuffer struct {
+	buf   []byte
+	start int // index of the first byte
+	size  int // number of buffered bytes
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{buf: make([]byte, capacity)}
+}
+
+// Len returns the number of buffered bytes
+func (b *ringBuffer) Len() int {
+	return b.size
+}
+
+// Free returns the number of bytes that can be written without overwriting buffered data
+func (b *ringBuffer) Free() int {
+	return len(b.buf) - b.size
+}
+
+// Write appends as much of p as fits and returns the number of bytes written
+func (b *ringBuffer) Write(p []byte) int {
+	written := 0
+	for len(p) > 0 && b.size < len(b.buf) {
+		end := (b.start + b.size) % len(b.buf)
+		limit := len(b.buf)
+		if end < b.start {
+			limit = b.start
+		}
+
+		n := copy(b.buf[end:limit], p)
+		b.size += n
+		written += n
+		p = p[n:]
+	}
+	return written
+}
+
+// Read removes up to len(p) bytes from the buffer into p and returns their number
+func (b *ringBuffer) Read(p []byte) int {
+	read := 0
+	for len(p) > 0 && b.size > 0 {
+		limit := min(b.start+b.size, len(b.buf))
+
+		n := copy(p, b.buf[b.start:limit])
+		b.start = (b.start + n) % len(b.buf)
+		b.size -= n
+		read += n
+		p = p[n:]
+	}
+	if b.size == 0 {
+		b.start = 0
+	}
+	return read
+}
+
+// Reset discards all buffered data
+func (b *ringBuffer) Reset() {
+	b.start = 0
+	b.size = 0
+}
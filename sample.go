@@ -0,0 +1,61 @@
+package tailreader
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// SampleReader forwards a sample of the records of a RecordSource
+//
+// It's meant for high-volume streams where consumers only need a sample; the
+// records left out are counted by Dropped.
+type SampleReader struct {
+	src      RecordSource
+	every    int     // forward every Nth record, 0 if sampling randomly
+	fraction float64 // probability of a record to be forwarded if sampling randomly
+	count    int     // records read since the last one forwarded (modulo every)
+	dropped  atomic.Uint64
+}
+
+// NewSampleReader returns a SampleReader forwarding every nth record, starting with the first
+func NewSampleReader(src RecordSource, n int) *SampleReader {
+	return &SampleReader{
+		src:   src,
+		every: max(n, 1),
+	}
+}
+
+// NewRandomSampleReader returns a SampleReader forwarding each record with the probability fraction
+func NewRandomSampleReader(src RecordSource, fraction float64) *SampleReader {
+	return &SampleReader{
+		src:      src,
+		fraction: fraction,
+	}
+}
+
+// ReadRecord returns the next record of the sample
+func (s *SampleReader) ReadRecord() (Record, error) {
+	for {
+		rec, err := s.src.ReadRecord()
+		if err != nil || s.selects() {
+			return rec, err
+		}
+		s.dropped.Add(1)
+	}
+}
+
+// selects decides whether the record just read is forwarded
+func (s *SampleReader) selects() bool {
+	if s.every == 0 {
+		return rand.Float64() < s.fraction
+	}
+
+	forward := s.count == 0
+	s.count = (s.count + 1) % s.every
+	return forward
+}
+
+// Dropped returns the number of records left out of the sample
+func (s *SampleReader) Dropped() uint64 {
+	return s.dropped.Load()
+}
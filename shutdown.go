@@ -0,0 +1,55 @@
+package tailreader
+
+import (
+	"context"
+	"errors"
+)
+
+// Shutdown closes the reader after the data that is already readable has been read
+//
+// Reads no longer wait for new data or for the file to be created but return
+// io.EOF once they have caught up. Shutdown waits until a Read has returned that
+// io.EOF or until ctx is done, whichever comes first, then saves a checkpoint
+// (if a CheckpointStore is set) and closes the reader. Data must still be read
+// by the consumer while Shutdown waits.
+func (r *TailingReader) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if r.isClosed() {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+	r.draining = true
+	drained := r.drained
+	if drained == nil {
+		drained = make(chan struct{})
+		r.drained = drained
+	}
+	r.mu.Unlock()
+
+	// a pending Read may be waiting for changes
+	r.wake()
+
+	var err error
+	select {
+	case <-drained:
+	case <-r.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if r.options.CheckpointStore != nil {
+		err = errors.Join(err, r.SaveCheckpoint())
+	}
+	return errors.Join(err, r.Close())
+}
+
+// endDrain signals Shutdown that a Read has returned io.EOF while draining
+//
+// It must be called with r.mu held.
+func (r *TailingReader) endDrain() {
+	if r.draining && r.drained != nil {
+		close(r.drained)
+		r.drained = nil
+	}
+}
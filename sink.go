@@ -0,0 +1,123 @@
+package tailreader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSinkMinBackoff and DefaultSinkMaxBackoff limit the delay between deliveries to a failing sink if no limits are set
+	DefaultSinkMinBackoff = 100 * time.Millisecond
+	DefaultSinkMaxBackoff = 30 * time.Second
+)
+
+// Sink is an output the Manager writes the records of its files to
+//
+// Outputs (files, sockets, message queues) implement it independently of the
+// tailing logic. Write may buffer records; Flush is called after each batch
+// of records and returns once they are delivered. The Manager doesn't call
+// the methods of a sink concurrently.
+//
+// When retrying a failed delivery, the Manager writes the records again from
+// the first one Write failed for, and flushes. So a sink must keep the records
+// written successfully until a Flush succeeds, but not the record of a failed
+// Write, even if Write failed flushing a full batch.
+type Sink interface {
+	Write(ctx context.Context, rec Record) error
+	Flush(ctx context.Context) error
+}
+
+// DeliveryMode determines whether the checkpoints of files are saved before or after records are delivered to a sink
+type DeliveryMode int
+
+const (
+	// AtLeastOnce saves checkpoints once the sink has acknowledged the records, so they may be delivered again after a crash
+	AtLeastOnce DeliveryMode = iota
+
+	// AtMostOnce saves checkpoints before delivering the records to the sink, so they may be lost after a crash
+	AtMostOnce
+)
+
+// SinkOption configures a sink added to a Manager
+type SinkOption func(s *managedSink)
+
+// WithSinkRetries sets how often failed deliveries to the sink are retried before it fails the run
+//
+// A negative number retries indefinitely. The delay between retries doubles
+// from minBackoff up to maxBackoff; 0 uses DefaultSinkMinBackoff and
+// DefaultSinkMaxBackoff. Without this option, the first failure fails the run.
+func WithSinkRetries(retries int, minBackoff time.Duration, maxBackoff time.Duration) SinkOption {
+	return func(s *managedSink) {
+		s.retries = retries
+		s.minBackoff = minBackoff
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// WithDeliveryMode sets the delivery mode of the sink (AtLeastOnce by default)
+func WithDeliveryMode(mode DeliveryMode) SinkOption {
+	return func(s *managedSink) {
+		s.mode = mode
+	}
+}
+
+// managedSink serializes the calls to a sink shared by the files of a Manager
+type managedSink struct {
+	name string
+	mu   sync.Mutex
+	sink Sink
+	wal  *wal // log the records are delivered from, nil if they are delivered directly
+
+	mode       DeliveryMode
+	retries    int // retries of a failed delivery, negative if unlimited
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// deliver writes the records to the sink and flushes it, retrying failures up to retries times (indefinitely if negative)
+//
+// Records written before a failure are not written again, as sinks keep
+// them until flushing succeeds (see Sink).
+func (s *managedSink) deliver(ctx context.Context, records []Record, retries int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	written := 0
+	backoff := time.Duration(0)
+	for attempt := 0; ; attempt++ {
+		var err error
+		for written < len(records) && err == nil {
+			err = s.sink.Write(ctx, records[written])
+			if err == nil {
+				written++
+			}
+		}
+		if err == nil {
+			err = s.sink.Flush(ctx)
+		}
+		if err == nil || retries >= 0 && attempt >= retries {
+			return err
+		}
+
+		backoff = s.nextBackoff(backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// nextBackoff doubles backoff within the sink's limits
+func (s *managedSink) nextBackoff(backoff time.Duration) time.Duration {
+	minBackoff := s.minBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultSinkMinBackoff
+	}
+	maxBackoff := s.maxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultSinkMaxBackoff
+	}
+	return min(max(2*backoff, minBackoff), maxBackoff)
+}
@@ -0,0 +1,131 @@
+package tailreader
+
+import (
+	"io"
+	"os"
+)
+
+// spillFile is a FIFO buffer in a temporary file for data that doesn't fit into memory
+type spillFile struct {
+	file    *os.File
+	readOff int64 // offset of the first buffered byte
+	size    int64 // offset the next byte is written at
+}
+
+func newSpillFile(dir string) (*spillFile, error) {
+	file, err := os.CreateTemp(dir, "tailreader-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{file: file}, nil
+}
+
+// Len returns the number of buffered bytes
+func (s *spillFile) Len() int64 {
+	return s.size - s.readOff
+}
+
+// Write appends p to the file
+func (s *spillFile) Write(p []byte) error {
+	n, err := s.file.WriteAt(p, s.size)
+	s.size += int64(n)
+	return err
+}
+
+// Read removes up to len(p) bytes from the file into p
+func (s *spillFile) Read(p []byte) (int, error) {
+	if int64(len(p)) > s.Len() {
+		p = p[:s.Len()]
+	}
+
+	n, err := s.file.ReadAt(p, s.readOff)
+	s.readOff += int64(n)
+	if n == 0 {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	if s.Len() == 0 {
+		// start over to keep the file from growing while data passes through; if
+		// truncating fails, the file is overwritten from the beginning anyway
+		_ = s.Reset()
+	}
+	return n, nil
+}
+
+// Reset discards all buffered data
+func (s *spillFile) Reset() error {
+	s.readOff = 0
+	s.size = 0
+	return s.file.Truncate(0)
+}
+
+// Close closes and removes the file
+func (s *spillFile) Close() error {
+	err := s.file.Close()
+	if removeErr := os.Remove(s.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// prefetchRoom returns the number of bytes that can be read in the background without pausing
+func (r *TailingReader) prefetchRoom() int {
+	if r.spill == nil || r.spill.Len() == 0 {
+		if free := r.prefetched.Free(); free > 0 {
+			return free
+		}
+	}
+
+	if r.options.SpillDir == "" {
+		return 0
+	}
+
+	room := int64(prefetchChunkSize)
+	if r.options.MaxSpillSize > 0 {
+		spilled := int64(0)
+		if r.spill != nil {
+			spilled = r.spill.Len()
+		}
+		room = min(room, r.options.MaxSpillSize-spilled)
+	}
+	return int(max(room, 0))
+}
+
+// storePrefetched buffers data read in the background, in memory if there's room and in the spill file otherwise
+//
+// p must not be larger than prefetchRoom.
+func (r *TailingReader) storePrefetched(p []byte) error {
+	if (r.spill == nil || r.spill.Len() == 0) && r.prefetched.Free() > 0 {
+		r.prefetched.Write(p)
+		return nil
+	}
+
+	if r.spill == nil {
+		spill, err := newSpillFile(r.options.SpillDir)
+		if err != nil {
+			return err
+		}
+		r.spill = spill
+	}
+	return r.spill.Write(p)
+}
+
+// takePrefetched removes data read in the background into p, oldest first
+func (r *TailingReader) takePrefetched(p []byte) (int, error) {
+	n := r.prefetched.Read(p)
+	if n == 0 && r.spill != nil && r.spill.Len() > 0 {
+		return r.spill.Read(p)
+	}
+	return n, nil
+}
+
+// spilled returns the number of bytes in the spill file
+func (r *TailingReader) spilled() int64 {
+	if r.spill == nil {
+		return 0
+	}
+	return r.spill.Len()
+}
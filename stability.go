@@ -0,0 +1,41 @@
+package tailreader
+
+import (
+	"io"
+	"time"
+)
+
+// settleTime returns how long to wait before reading the data up to size, or 0 if it can be read now
+//
+// Data is read once the file's size hasn't changed for StabilityWindow or if it
+// ends with a newline, so records written in multiple small writes aren't torn.
+func (r *TailingReader) settleTime(size int64) time.Duration {
+	window := r.options.StabilityWindow
+	if window <= 0 {
+		return 0
+	}
+
+	now := r.now()
+	if size != r.stableSize {
+		r.stableSize = size
+		r.stableSince = now
+	}
+
+	remaining := window - now.Sub(r.stableSince)
+	if remaining <= 0 || r.endsWithNewline(size) {
+		return 0
+	}
+	return remaining
+}
+
+// endsWithNewline reports whether the last byte before size is a newline
+func (r *TailingReader) endsWithNewline(size int64) bool {
+	readerAt, ok := r.file.(io.ReaderAt)
+	if !ok || size == 0 {
+		return false
+	}
+
+	var last [1]byte
+	n, _ := readerAt.ReadAt(last[:], size-1)
+	return n == 1 && last[0] == '\n'
+}
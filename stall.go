@@ -0,0 +1,43 @@
+package tailreader
+
+import "time"
+
+// untilStall returns how long until the reader is stalled, or 0 if it is already (or StallTimeout isn't set)
+//
+// OnStall is called once the reader hasn't read any data for StallTimeout.
+func (r *TailingReader) untilStall() time.Duration {
+	if r.options.StallTimeout <= 0 || r.stalled {
+		return 0
+	}
+
+	now := r.now()
+	if r.lastData.IsZero() {
+		r.lastData = now
+	}
+
+	remaining := r.options.StallTimeout - now.Sub(r.lastData)
+	if remaining > 0 {
+		return remaining
+	}
+
+	r.stalled = true
+	if r.options.OnStall != nil {
+		r.options.OnStall(true)
+	}
+	return 0
+}
+
+// recordData notes that data was read, which ends a stall
+func (r *TailingReader) recordData() {
+	if r.options.StallTimeout <= 0 {
+		return
+	}
+
+	r.lastData = r.now()
+	if r.stalled {
+		r.stalled = false
+		if r.options.OnStall != nil {
+			r.options.OnStall(false)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package tailreader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// searchWindow is the size of the region below which searching for a time switches to scanning records
+const searchWindow = 64 * 1024
+
+// TimeParser extracts the timestamp of a record, returning false if it has none
+type TimeParser func(data []byte) (time.Time, bool)
+
+// WithStartAfterTime starts reading at the first record with a timestamp after t
+//
+// The existing content is binary searched for records before t (assuming
+// timestamps are ascending), and records are skipped from there until one is
+// newer than t. Records without a timestamp are skipped along with the older
+// ones; from the first newer record on, everything is returned.
+func WithStartAfterTime(t time.Time, parse TimeParser) RecordOption {
+	return func(rr *RecordReader) {
+		rr.startAfter = t
+		rr.parseTime = parse
+		rr.skipping = true
+	}
+}
+
+// skip checks whether rec is to be skipped because it's not after the start time
+func (rr *RecordReader) skip(rec Record) bool {
+	if !rr.skipping {
+		return false
+	}
+
+	ts, ok := rr.parseTime(rec.Data)
+	if ok && ts.After(rr.startAfter) {
+		rr.skipping = false
+		return false
+	}
+	return true
+}
+
+// seekStartTime moves the reader close to the first record after the start time
+func (rr *RecordReader) seekStartTime() error {
+	var offset int64
+	err := rr.tr.withReaderAt(func(f io.ReaderAt, size int64) (err error) {
+		offset, err = searchTime(f, size, rr.startAfter, rr.parseTime)
+		return err
+	})
+	if err != nil || offset == 0 {
+		return err
+	}
+
+	_, err = rr.tr.Seek(offset, io.SeekStart)
+	return err
+}
+
+// withReaderAt calls fn with a separate handle of the file if it supports random access
+func (r *TailingReader) withReaderAt(fn func(f io.ReaderAt, size int64) error) error {
+	r.mu.Lock()
+	fsys, path := r.fs, r.filePath
+	r.mu.Unlock()
+
+	file, err := fsys.OpenFile(path, os.O_RDONLY)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	f, ok := file.(io.ReaderAt)
+	if !ok {
+		return nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return fn(f, info.Size())
+}
+
+// searchTime returns the offset of a record not after t that is as close as possible to the first one after t
+//
+// It returns 0 if there's no such record (or the region to scan is small anyway).
+func searchTime(f io.ReaderAt, size int64, t time.Time, parse TimeParser) (int64, error) {
+	lo, hi := int64(0), size
+	for hi-lo > searchWindow {
+		mid := lo + (hi-lo)/2
+
+		start, ts, ok, err := firstTimestamp(f, mid, hi, size, parse)
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok || ts.After(t) {
+			hi = mid
+		} else {
+			lo = start
+		}
+	}
+	return lo, nil
+}
+
+// firstTimestamp returns the offset and timestamp of the first record with a timestamp starting in [from, to)
+func firstTimestamp(f io.ReaderAt, from int64, to int64, size int64, parse TimeParser) (int64, time.Time, bool, error) {
+	pos := from
+	if from > 0 {
+		// from is only a record's start if it follows a newline
+		pos--
+	}
+
+	br := bufio.NewReader(io.NewSectionReader(f, pos, size-pos))
+	if from > 0 {
+		skipped, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return 0, time.Time{}, false, nil
+		} else if err != nil {
+			return 0, time.Time{}, false, err
+		}
+		pos += int64(len(skipped))
+	}
+
+	for pos < to {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return 0, time.Time{}, false, err
+		}
+
+		if ts, ok := parse(bytes.TrimSuffix(line, []byte{'\n'})); ok {
+			return pos, ts, true, nil
+		}
+
+		if err == io.EOF {
+			break
+		}
+		pos += int64(len(line))
+	}
+
+	return 0, time.Time{}, false, nil
+}
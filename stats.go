@@ -0,0 +1,120 @@
+package tailreader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"time"
+)
+
+// Checksum is the algorithm of the running checksum of delivered data
+type Checksum string
+
+const (
+	ChecksumCRC32  Checksum = "crc32"
+	ChecksumSHA256 Checksum = "sha256"
+)
+
+func (c Checksum) newHash() hash.Hash {
+	switch c {
+	case ChecksumCRC32:
+		return crc32.NewIEEE()
+	case ChecksumSHA256:
+		return sha256.New()
+	}
+	return nil
+}
+
+// Stats are statistics of a reader
+type Stats struct {
+	Path       string // path of the file
+	Offset     int64  // offset of the next byte delivered by Read
+	Delivered  int64  // number of bytes delivered by Read since the reader was created
+	Records    int64  // number of newlines delivered by Read since the reader was created
+	Lag        int64  // number of bytes in the file after Offset, i.e. not yet delivered
+	Generation int64  // number of times reading started over at the beginning of a new (or truncated) file
+
+	// Checksum is the hex encoded checksum of all delivered data (see WithChecksum),
+	// including the data delivered before the checkpoint the reader resumed from
+	Checksum string
+
+	// Interval is the time since the previous call of OnStats (see StatsInterval),
+	// during which IntervalBytes bytes and IntervalRecords newlines were delivered;
+	// these fields are only set for OnStats
+	Interval        time.Duration
+	IntervalBytes   int64
+	IntervalRecords int64
+}
+
+// Stats returns the current statistics of the reader
+func (r *TailingReader) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := Stats{
+		Path:       r.filePath,
+		Offset:     r.position(),
+		Delivered:  r.delivered,
+		Records:    r.records,
+		Generation: r.generation,
+	}
+	if r.fs != nil {
+		if info, err := r.statFile(); err == nil {
+			stats.Lag = max(info.Size()-stats.Offset, 0)
+		}
+	}
+	if r.hash != nil {
+		stats.Checksum = hex.EncodeToString(r.hash.Sum(nil))
+	}
+
+	return stats
+}
+
+// deliver accounts for data returned by Read
+func (r *TailingReader) deliver(p []byte) {
+	r.delivered += int64(len(p))
+	r.records += int64(bytes.Count(p, []byte{'\n'}))
+	if r.hash != nil {
+		// data delivered again after unread was hashed already
+		skip := min(len(p), r.rehash)
+		r.rehash -= skip
+		r.hash.Write(p[skip:])
+	}
+	if r.rateLimit() > 0 {
+		r.rateTokens -= float64(len(p))
+	}
+}
+
+// startStats starts calling OnStats every StatsInterval until the reader is closed
+func (r *TailingReader) startStats() {
+	interval, onStats := r.options.StatsInterval, r.options.OnStats
+	if interval == 0 || onStats == nil {
+		return
+	}
+
+	clock := r.clock()
+	prev, prevTime := r.Stats(), clock.Now()
+	go func() {
+		timer := clock.NewTimer(interval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-r.closed:
+				return
+			case <-timer.C():
+			}
+
+			stats, now := r.Stats(), clock.Now()
+			stats.Interval = now.Sub(prevTime)
+			stats.IntervalBytes = stats.Delivered - prev.Delivered
+			stats.IntervalRecords = stats.Records - prev.Records
+			onStats(stats)
+
+			prev, prevTime = stats, now
+			timer.Reset(interval)
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package tailreader
+
+// syncDir is a no-op where directories can't be synced; renames are durable once they return
+func syncDir(dir string) error {
+	return nil
+}
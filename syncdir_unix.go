@@ -0,0 +1,18 @@
+//go:build unix
+
+package tailreader
+
+import "os"
+
+// syncDir flushes the entries of dir, e.g. after renaming a file into it
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
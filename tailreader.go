@@ -16,9 +16,12 @@ package tailreader
 import (
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -30,23 +33,173 @@ var DefaultOptions = []Option{
 }
 
 type TailingReader struct {
-	file     *os.File
+	file     fs.File
 	filePath string
+	paths    []string // candidate paths in order of preference, see FallbackPaths
 	options  *Options
 	watcher  *fsnotify.Watcher
 	offset   int64
+
+	generation int64 // number of times reading started over at the beginning of a file, see Generation
+
+	fs        FS
+	osFile    *os.File  // file as *os.File if it is one, nil otherwise
+	polling   bool      // whether changes are detected by polling instead of file system notifications
+	detached  bool      // whether the open file is followed after it was removed from its path, see FollowDescriptor
+	pollState pollState // last observed state of the file when polling
+
+	mu           sync.Mutex    // held while reading (but not while waiting for events)
+	closed       chan struct{} // closed by Close to wake up a pending Read
+	caughtUp     chan struct{} // closed once all existing data has been read
+	wakeup       chan struct{} // wakes up a pending Read after its state was changed
+	closeOnce    sync.Once
+	caughtUpOnce sync.Once
+
+	optionsMu      sync.Mutex
+	pendingOptions []Option // options set by UpdateOptions, applied by the next read attempt
+
+	mmap       []byte // memory mapping used while catching up, nil if not mapped
+	mmapOffset int64  // file offset of mmap[0]
+
+	advisedOffset int64 // offset up to which pages have been advised as no longer needed
+
+	noWaitUnsupported bool // set once the kernel rejected a non-blocking read
+
+	direct    bool   // whether the file was opened for direct I/O
+	directBuf []byte // aligned buffer for direct I/O reads
+
+	readahead  []byte // buffer for reading ahead of the caller's buffer
+	pending    []byte // data read ahead but not yet returned by Read
+	unreadData []byte // data returned after Read, delivered before anything else, see unread
+	rehash     int    // number of bytes at the start of unreadData that were hashed already
+
+	prefetched    *ringBuffer   // data read in the background but not yet returned by Read, see Prefetch
+	prefetchErr   error         // error that ended reading in the background, returned by Read after prefetched
+	prefetchReady chan struct{} // signals Read that prefetched or prefetchErr was updated, nil if not prefetching
+	prefetchFreed chan struct{} // signals the background reader that Read took data
+	spill         *spillFile    // prefetched data that didn't fit into memory, see SpillDir
+
+	rateTokens  float64   // bytes that may be delivered before waiting, see RateLimit
+	rateTime    time.Time // time rateTokens was last updated
+	rateStarted bool      // whether the token bucket was filled initially
+
+	batchErr  error     // error to return by the next ReadBatch
+	hash      hash.Hash // running checksum of delivered data, see Checksum
+	delivered int64     // number of bytes delivered by Read
+	records   int64     // number of newlines delivered by Read
+
+	consumed      []byte // end of the data read from the file, see DetectModification
+	verifyPending bool   // whether consumed should be verified before reading on
+
+	lockFile  *os.File // lock file held while reading, see LockFile
+	watched   string   // path currently watched for changes
+	target    string   // target of the file if it's a symlink, see watchTarget
+	targetDir string   // directory watched for changes of target
+
+	version     string // last observed version of the file, see VersionedFileInfo
+	versionSize int64  // size of the file when its version was last observed
+	rewritten   bool   // whether the file was rewritten in place
+
+	stableSize  int64     // last observed size of the file, see StabilityWindow
+	stableSince time.Time // time the file was first observed at stableSize
+
+	shrunkSince time.Time // time the file was first observed smaller than offset, see NFS
+
+	growthSize     int64     // size of the file at growthTime, see GrowthLimit
+	growthTime     time.Time // time of the last measurement of the growth rate
+	growingSince   time.Time // time since which the file has grown faster than GrowthLimit
+	growthReported bool      // whether the current period of excessive growth was reported
+
+	lastData time.Time // time data was last read from the file, see StallTimeout
+	stalled  bool      // whether OnStall was called for the current period without data
+
+	draining bool          // whether reads return io.EOF instead of waiting, see Shutdown
+	drained  chan struct{} // closed once a read returned io.EOF while draining
 }
 
+// ErrIdleTimeout and ErrWaitTimeout are returned if IdleTimeout or WaitForFileTimeout is reached
+//
+// Timeouts don't affect the state of the reader: a subsequent Read continues
+// where the previous one left off and waits with a fresh timeout.
 var ErrIdleTimeout = fmt.Errorf("idle timeout")
 var ErrWaitTimeout = fmt.Errorf("wait for file timeout")
+var ErrClosed = fmt.Errorf("reader closed")
+var ErrWatcherFailed = fmt.Errorf("file system watcher failed")
+var ErrNotAFile = fmt.Errorf("not a file")
 var errTimeout = fmt.Errorf("timeout")
+var errInvalidWhence = fmt.Errorf("seek: invalid whence")
+var errOffsetOutOfRange = fmt.Errorf("seek: offset out of range")
 
 func NewTailingReader(filePath string, options ...Option) (*TailingReader, error) {
-	var err error
+	return newOSTailingReader(filePath, nil, 0, options)
+}
+
+// newOSTailingReader creates a reader for filePath; if file is set, it's read from offset unless a start position is configured
+func newOSTailingReader(filePath string, file *os.File, offset int64, options []Option) (*TailingReader, error) {
+	tr, err := newTailingReader(filePath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if tr.options.FS != nil {
+		// there are no file system notifications for custom file systems
+		tr.startPolling(tr.options.FS)
+	} else if tr.options.NFS {
+		// nor for remote file systems
+		tr.startPolling(osFS{})
+	} else {
+		err = tr.startWatching()
+		if err != nil {
+			_ = tr.Close()
+			return nil, err
+		}
+	}
+
+	tr.offset = offset
+	err = tr.initOffset()
+	if err == nil && file != nil {
+		err = tr.useFile(file, offset)
+	}
+	if err != nil {
+		_ = tr.Close()
+		return nil, err
+	}
+
+	tr.startStats()
+	return tr, nil
+}
+
+// NewTailingReaderFS creates a reader for the named file in fsys
+//
+// As there are no file system notifications for fs.FS, changes are detected
+// by polling the file's size and modification time (see WithPollInterval).
+// Features that need an operating system file (e.g. WithMmap, WithDirectIO)
+// are only used if fsys returns *os.File values.
+func NewTailingReaderFS(fsys fs.FS, name string, options ...Option) (*TailingReader, error) {
+	tr, err := newTailingReader(name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.startPolling(ioFS{fsys})
+
+	err = tr.initOffset()
+	if err != nil {
+		_ = tr.Close()
+		return nil, err
+	}
+
+	tr.startStats()
+	return tr, nil
+}
 
+func newTailingReader(filePath string, options []Option) (*TailingReader, error) {
 	tr := &TailingReader{
 		filePath: filePath,
 		options:  &Options{},
+		closed:   make(chan struct{}),
+		caughtUp: make(chan struct{}),
+		wakeup:   make(chan struct{}, 1),
 	}
 
 	if len(options) == 0 {
@@ -56,71 +209,544 @@ func NewTailingReader(filePath string, options ...Option) (*TailingReader, error
 		option(tr.options)
 	}
 
-	tr.watcher, err = fsnotify.NewWatcher()
+	err := tr.options.validate()
 	if err != nil {
 		return nil, err
 	}
 
-	path := filepath.Dir(filePath)
-	err = tr.watcher.Add(path)
+	tr.hash = tr.options.Checksum.newHash()
+
+	if len(tr.options.FallbackPaths) > 0 || tr.options.Glob {
+		tr.paths = append([]string{filePath}, tr.options.FallbackPaths...)
+	}
+
+	if tr.options.Glob {
+		for _, pattern := range tr.paths {
+			err = validatePattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return tr, nil
+}
+
+// NewTailingReaderFromFile creates a reader for an already opened file
+//
+// Reading starts at the file's current position, unless a start position is
+// configured (e.g. WithStartAtEnd or a checkpoint). The reader takes ownership
+// of the file and closes it on Close, or if it can't be created; the file's
+// name is used to watch for changes and to reopen the file after it was
+// deleted or truncated.
+func NewTailingReaderFromFile(file *os.File, options ...Option) (*TailingReader, error) {
+	offset, err := file.Seek(0, io.SeekCurrent)
 	if err != nil {
+		_ = file.Close()
 		return nil, err
 	}
 
+	tr, err := newOSTailingReader(file.Name(), file, offset, options)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
 	return tr, nil
 }
 
+// useFile continues reading with file, which is at offset, instead of opening the file by its path
+func (r *TailingReader) useFile(file *os.File, offset int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := r.offset
+	_ = r.closeFile()
+	r.offset = start
+	r.setFile(file)
+
+	if start != offset {
+		_, err := file.Seek(start, io.SeekStart)
+		if err != nil {
+			r.file, r.osFile = nil, nil
+			return err
+		}
+	}
+	return nil
+}
+
+// initOffset sets the offset to start reading at
+//
+// A checkpoint (see WithCheckpointStore) takes precedence over StartAtPattern,
+// which takes precedence over StartAtEnd.
+func (r *TailingReader) initOffset() error {
+	_, err := r.failover()
+	if err != nil {
+		return err
+	}
+
+	cp, ok, err := r.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+	if ok {
+		return r.restoreCheckpoint(cp)
+	}
+
+	if len(r.options.StartAtPattern) > 0 {
+		offset, found, err := r.findPattern()
+		if err != nil {
+			return err
+		}
+		if found {
+			r.offset = offset
+			return nil
+		}
+	}
+
+	if r.options.StartAtEnd || len(r.options.StartAtPattern) > 0 {
+		size, err := r.getFileSize()
+		if err == nil {
+			r.offset = size
+		}
+	}
+
+	return nil
+}
+
+// Close closes the reader and the underlying file
+//
+// A pending Read returns ErrClosed, as does any Read after Close.
+// It is safe to call Close multiple times; subsequent calls return nil.
 func (r *TailingReader) Close() error {
-	err := r.watcher.Close()
-	r.watcher = nil
+	var err error
+	r.closeOnce.Do(func() {
+		err = r.close()
+	})
+	return err
+}
+
+func (r *TailingReader) close() error {
+	close(r.closed)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watcher != nil {
+		err := r.watcher.Close()
+		r.watcher = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.spill != nil {
+		_ = r.spill.Close()
+		r.spill = nil
+	}
+
+	err := r.releaseLock()
 	if err != nil {
 		return err
 	}
 	return r.closeFile()
 }
 
+func (r *TailingReader) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
 func (r *TailingReader) openFile() error {
 	if r.file != nil {
 		return nil
 	}
 
-	file, err := os.Open(r.filePath)
+	file, err := r.fs.OpenFile(r.filePath, os.O_RDONLY|r.openFlags())
 	if err != nil {
 		return err
 	}
 
-	r.file = file
-	r.offset = 0
+	if r.offset > 0 {
+		// continue at the offset that was kept when the file was reopened
+		err = seekFile(file, r.offset)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	r.setFile(file)
 
 	return nil
 }
 
+func (r *TailingReader) setFile(file fs.File) {
+	r.file = file
+	r.osFile, _ = file.(*os.File)
+	r.direct = r.options.DirectIO && r.osFile != nil
+	r.advisedOffset = 0
+
+	if r.options.Fadvise && r.osFile != nil {
+		r.adviseSequential()
+	}
+}
+
+// restartFile closes the file to continue reading at the beginning of a new (or truncated) one
+func (r *TailingReader) restartFile() error {
+	r.generation++
+	return r.closeFile()
+}
+
+// restartTruncated starts over at the beginning of the truncated file, see SeekOnTruncate
+func (r *TailingReader) restartTruncated() {
+	if r.options.OnTruncate != nil {
+		r.options.OnTruncate(r.offset)
+	}
+
+	if !r.options.SeekOnTruncate || r.options.CloseOnTruncate || r.file == nil {
+		_ = r.restartFile()
+		return
+	}
+
+	_ = r.unmapFile()
+	err := seekFile(r.file, 0)
+	if err != nil {
+		_ = r.restartFile()
+		return
+	}
+
+	r.generation++
+	r.offset = 0
+	r.pending = nil
+	r.consumed = r.consumed[:0]
+	r.advisedOffset = 0
+}
+
 func (r *TailingReader) closeFile() error {
 	if r.file == nil {
 		return nil
 	}
 
+	_ = r.unmapFile()
+
 	err := r.file.Close()
 	r.file = nil
+	r.osFile = nil
 	r.offset = 0
+	r.pending = nil
+	r.consumed = r.consumed[:0]
+	r.detached = false
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateOptions changes the options of the reader
+//
+// The resulting options are validated before they are accepted. It is safe to
+// call UpdateOptions while another goroutine is blocked in Read. The options
+// are applied before the next read attempt, so a pending wait still uses the
+// previous timeouts. DirectIO takes effect when the file is (re)opened.
+func (r *TailingReader) UpdateOptions(options ...Option) error {
+	r.optionsMu.Lock()
+	defer r.optionsMu.Unlock()
+
+	opts := *r.options
+	for _, option := range r.pendingOptions {
+		option(&opts)
+	}
+	for _, option := range options {
+		option(&opts)
+	}
 
+	err := opts.validate()
 	if err != nil {
 		return err
 	}
 
+	r.pendingOptions = append(r.pendingOptions, options...)
 	return nil
 }
 
+func (r *TailingReader) applyPendingOptions() {
+	r.optionsMu.Lock()
+	defer r.optionsMu.Unlock()
+
+	for _, option := range r.pendingOptions {
+		option(r.options)
+	}
+	r.pendingOptions = nil
+}
+
+// FilePath returns the path of the file being read
+func (r *TailingReader) FilePath() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.filePath
+}
+
+// Generation returns the number of times reading started over at the beginning of a new
+// (or truncated) file, e.g. after log rotation
+func (r *TailingReader) Generation() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.generation
+}
+
+// Offset returns the file offset of the next byte returned by Read
+func (r *TailingReader) Offset() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.position()
+}
+
+// position returns the file offset of the next byte returned by Read
+func (r *TailingReader) position() int64 {
+	position := r.offset - int64(len(r.pending)) - int64(len(r.unreadData))
+	if r.prefetched != nil {
+		position -= int64(r.prefetched.Len()) + r.spilled()
+	}
+	return position
+}
+
+// CaughtUp returns a channel that is closed once the reader has caught up with the file
+//
+// This happens the first time Read has returned all data up to the end of the
+// file and starts waiting for new data, i.e. when the reader transitions from
+// catching up on pre-existing data to tailing live writes.
+func (r *TailingReader) CaughtUp() <-chan struct{} {
+	return r.caughtUp
+}
+
+// Seek sets the offset for the next Read, see io.Seeker
+//
+// It can be called while another goroutine is blocked in Read, which then
+// continues at the new offset. Seeking beyond the end of the file is not
+// allowed, as the reader couldn't tell that apart from truncation.
+func (r *TailingReader) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return 0, ErrClosed
+	}
+
+	size, err := r.getFileSize()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.position()
+	case io.SeekEnd:
+		offset += size
+	default:
+		return 0, errInvalidWhence
+	}
+
+	if offset < 0 || offset > size {
+		return 0, errOffsetOutOfRange
+	}
+
+	err = r.closeFile()
+	r.discardPrefetched()
+	r.offset = offset
+	r.wake()
+
+	return offset, err
+}
+
+// Reopen closes and reopens the file
+//
+// If keepOffset is true, reading continues at the current offset in the
+// reopened file, otherwise it starts from the beginning.
+func (r *TailingReader) Reopen(keepOffset bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return ErrClosed
+	}
+
+	offset := r.position()
+	err := r.closeFile()
+	if err != nil {
+		return err
+	}
+	r.discardPrefetched()
+
+	if keepOffset {
+		r.offset = offset
+	} else {
+		r.generation++
+	}
+	r.wake()
+
+	return r.openFile()
+}
+
+// ReopenIfReplaced reopens the file if its path refers to a different file now
+//
+// Reading continues at the beginning of the new file. Unlike Reopen(false), a
+// file that wasn't replaced (e.g. by log rotation) isn't read again. Files not
+// opened from the operating system's file system are never considered replaced.
+// It returns whether the file was reopened.
+func (r *TailingReader) ReopenIfReplaced() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return false, ErrClosed
+	}
+
+	if r.osFile == nil {
+		// either not open yet (the next read opens the path anyway) or not comparable
+		return false, nil
+	}
+
+	info, err := r.fs.Stat(r.filePath)
+	if err != nil {
+		// nothing to switch to (yet)
+		return false, nil
+	}
+	current, err := r.osFile.Stat()
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(info, current) {
+		return false, nil
+	}
+
+	err = r.restartFile()
+	if err != nil {
+		return false, err
+	}
+	r.discardPrefetched()
+	r.wake()
+
+	return true, r.openFile()
+}
+
+// Reset resets the reader to offset 0 and discards any buffered data
+//
+// The file is reopened by the next Read.
+func (r *TailingReader) Reset() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return ErrClosed
+	}
+
+	err := r.restartFile()
+	r.offset = 0
+	r.pending = nil
+	r.discardPrefetched()
+	r.wake()
+
+	return err
+}
+
+// SetFilePath switches the reader to a different file
+//
+// The watch is moved to the new file's directory and reading starts at offset 0
+// of the new file. If the new directory cannot be watched, the reader keeps
+// tailing the current file.
+func (r *TailingReader) SetFilePath(filePath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return ErrClosed
+	}
+
+	oldPath := filepath.Dir(r.filePath)
+	path := filepath.Dir(filePath)
+	if r.watcher != nil && r.options.WatchFile {
+		_ = r.removeWatch(r.watched)
+		r.watched = ""
+	} else if r.watcher != nil && path != oldPath {
+		err := r.addWatch(path)
+		if err != nil {
+			return err
+		}
+		_ = r.removeWatch(oldPath)
+	}
+
+	err := r.restartFile()
+	r.filePath = filePath
+	r.paths = nil
+	r.offset = 0
+	r.pending = nil
+	r.discardPrefetched()
+	r.updateWatch()
+
+	if r.polling {
+		r.pollFile()
+	}
+	r.wake()
+
+	return err
+}
+
 func (r *TailingReader) getFileSize() (int64, error) {
-	fileInfo, err := os.Stat(r.filePath)
+	fileInfo, err := r.statFile()
 	if err != nil {
 		return 0, err
 	}
-	return fileInfo.Size(), nil
+	if fileInfo.IsDir() {
+		return 0, fmt.Errorf("%s: %w", r.filePath, ErrNotAFile)
+	}
+
+	if r.osFile != nil && !r.detached && r.options.StatFunc == nil {
+		// the path may refer to a different file than the open one by now (until
+		// the event for it arrives); its size says nothing about the open file
+		current, err := r.osFile.Stat()
+		if err == nil && !os.SameFile(fileInfo, current) {
+			fileInfo = current
+		}
+	}
+
+	size := fileInfo.Size()
+	version := fileVersion(fileInfo)
+	if version != r.version {
+		// the same size with a different version means the data was rewritten
+		r.rewritten = r.file != nil && size == r.offset && size == r.versionSize
+		r.version = version
+	}
+	r.versionSize = size
+
+	return size, nil
 }
 
 func (r *TailingReader) WaitForFile() error {
-	_, err := r.waitForFile(true)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return ErrClosed
+	}
+
+	r.applyPendingOptions()
+
+	err := r.acquireLock()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.waitForFile(true)
 	return err
 }
 
@@ -132,22 +758,44 @@ func (r *TailingReader) waitForFile(forceWait bool) (int64, error) {
 			return size, nil
 		}
 
-		// file does not exist
+		switched, failoverErr := r.failover()
+		if failoverErr != nil {
+			return 0, failoverErr
+		}
+		if switched {
+			// switched to a fallback path (or a file matching the pattern) that exists
+			continue
+		}
+
+		// file does not exist (or is a directory)
 
 		if !r.options.WaitForFile && !forceWait {
 			// if we don't want to wait for the file, return an error
 			return 0, err
 		}
+		if errors.Is(err, ErrNotAFile) && !r.options.WaitForRegularFile {
+			return 0, err
+		}
 
 		if r.file != nil {
 			// the file was already opened, but somehow disappeared
+			if !r.options.CloseOnDelete && r.detach() {
+				continue
+			}
+
+			// like on a remove event, a recreated file is read from the beginning
+			_ = r.restartFile()
 
 			if r.options.CloseOnDelete {
-				_ = r.closeFile()
 				return 0, io.EOF
 			}
 		}
 
+		if r.draining {
+			// see Shutdown
+			return 0, io.EOF
+		}
+
 		// wait for the file to be created
 		err, _ = r.waitForEventWithTimeout(fsnotify.Create, r.options.WaitForFileTimeout)
 		if errors.Is(err, errTimeout) {
@@ -164,46 +812,282 @@ func (r *TailingReader) waitForFile(forceWait bool) (int64, error) {
 }
 
 func (r *TailingReader) Read(p []byte) (n int, err error) {
+	n, _, err = r.read(p, time.Time{})
+	return n, err
+}
+
+// ReadAtOffset reads like Read and also returns the file offset of the first byte read
+//
+// Unlike calling Offset after Read, the offset is consistent with the data
+// even if Seek or Reset are called concurrently.
+func (r *TailingReader) ReadAtOffset(p []byte) (n int, off int64, err error) {
+	return r.read(p, time.Time{})
+}
+
+// read reads like ReadAtOffset, but returns errTimeout if no data arrived by deadline (unless it's zero)
+func (r *TailingReader) read(p []byte, deadline time.Time) (n int, off int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isClosed() {
+		return 0, 0, ErrClosed
+	}
+
+	err = r.acquireLock()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if limit := r.rateLimit(); limit > 0 {
+		err = r.throttle(limit)
+		if err != nil {
+			return 0, 0, err
+		}
+		if int64(len(p)) > limit {
+			p = p[:limit]
+		}
+	}
+
+	defer func() {
+		if n > 0 {
+			off = r.position() - int64(n)
+			r.deliver(p[:n])
+		}
+		if err == io.EOF {
+			r.endDrain()
+		}
+	}()
+
+	if len(r.unreadData) > 0 {
+		// returned by unread, so it comes before pending and prefetched data
+		n = copy(p, r.unreadData)
+		r.unreadData = r.unreadData[n:]
+		return n, 0, nil
+	}
+
+	if r.options.Prefetch || r.prefetchReady != nil {
+		// once started, reading in the background continues even if Prefetch is unset
+		n, err = r.readPrefetched(p, deadline)
+		return n, 0, err
+	}
+
+	if len(r.pending) > 0 {
+		// data that has already been read ahead
+		n = copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, 0, nil
+	}
+
+	n, err = r.fetch(p, deadline)
+	return n, 0, err
+}
+
+// fetch reads new data from the file into p, waiting for it if there is none
+//
+// It must be called with r.mu held.
+func (r *TailingReader) fetch(p []byte, deadline time.Time) (n int, err error) {
+	reopened := false
+	waitingSince := r.now()
+	idleSince := waitingSince // start of the current IdleTimeout
 	for {
+		r.applyPendingOptions()
+
 		size, err := r.waitForFile(false)
 		if err != nil {
 			return 0, err
 		}
 
-		if r.offset > size {
+		err = r.checkGrowth(size)
+		if err != nil {
+			return 0, err
+		}
+
+		var settle time.Duration
+		if r.tolerateShrink(size) {
+			// the size may be outdated; check again later
+			settle = r.pollInterval()
+		} else if r.offset > size || r.rewritten {
 			// file was (most likely) truncated
+			r.rewritten = false
 
-			_ = r.closeFile()
+			r.restartTruncated()
 
 			if r.options.CloseOnTruncate {
 				return 0, io.EOF
 			}
 		}
 
-		if r.offset < size {
-			// we have new data to read
+		if r.verifyPending {
+			r.verifyPending = false
+			err = r.verifyConsumed()
+			if err != nil {
+				return 0, err
+			}
+		}
 
+		if r.offset < size {
 			err = r.openFile()
 			if err != nil {
 				return 0, err
 			}
+			settle = r.settleTime(size)
+		}
 
-			n, err = r.file.Read(p)
+		if r.offset < size && settle == 0 {
+			locked, err := r.lockForRead()
+			if err != nil {
+				return 0, err
+			}
+			if !locked {
+				// a writer is in the middle of writing; check again later
+				settle = r.pollInterval()
+			}
+		}
+
+		if r.offset < size && settle == 0 {
+			// we have new data to read
+
+			buf := p
+			readingAhead := false
+			if readahead := r.readaheadSize(); len(p) < readahead {
+				if len(r.readahead) != readahead {
+					r.readahead = make([]byte, readahead)
+				}
+				buf = r.readahead
+				readingAhead = true
+			}
+			if r.options.StabilityWindow > 0 && int64(len(buf)) > size-r.offset {
+				// data appended since the size was checked hasn't settled yet
+				buf = buf[:size-r.offset]
+			}
+
+			n, err = r.readFile(buf, size)
+			r.unlockAfterRead()
 			if err != nil && err != io.EOF {
 				return 0, err
 			}
 
 			if n > 0 {
-				r.offset += int64(n)
+				r.advance(buf[:n])
+				if readingAhead {
+					// keep what doesn't fit into p for subsequent reads
+					r.pending = buf[:n]
+					n = copy(p, r.pending)
+					r.pending = r.pending[n:]
+				}
 				return n, nil
 			}
+
+			if r.osFile == nil && !reopened {
+				// files from an fs.FS may be snapshots taken when they were opened;
+				// reopen to see the new data
+				reopened = true
+				offset := r.offset
+				_ = r.closeFile()
+				r.offset = offset
+				continue
+			} else if r.options.NFS {
+				// the data may not be visible yet although the size was updated
+				settle = r.pollInterval()
+			}
 		}
 
+		if r.options.NoWaitRead && settle == 0 && r.osFile != nil && r.mmap == nil && !r.direct && r.options.StabilityWindow <= 0 {
+			// data might already be in the page cache even though we haven't been notified yet
+			// (not with direct I/O, which bypasses it, nor with a StabilityWindow, which only
+			// applies to data up to a checked size)
+			locked, err := r.lockForRead()
+			if err != nil {
+				return 0, err
+			}
+			if locked {
+				n, err = r.readNoWait(p)
+				r.unlockAfterRead()
+				if err != nil {
+					return 0, err
+				}
+
+				if n > 0 {
+					r.advance(p[:n])
+					return n, nil
+				}
+			}
+		}
+
+		if settle == 0 && !r.detached && r.unlinked() {
+			// deleted without an event for our path
+			if r.options.CloseOnDelete {
+				return 0, io.EOF
+			}
+			if !r.detach() {
+				_ = r.restartFile()
+			}
+			continue
+		}
+
+		if r.draining {
+			// see Shutdown
+			return 0, io.EOF
+		}
+
+		r.caughtUpOnce.Do(func() {
+			close(r.caughtUp)
+		})
+
 		// wait for changes to the file (fsnotify.Chmod is triggered on truncate)
-		err, event := r.waitForEventWithTimeout(fsnotify.Write|fsnotify.Remove|fsnotify.Rename|fsnotify.Chmod, r.options.IdleTimeout)
+		timeout := r.options.IdleTimeout
+		idleExpired := false
+		if timeout > 0 {
+			// wakeups without new data (settling, stalls, events) don't restart the idle timeout
+			timeout -= r.now().Sub(idleSince)
+			idleExpired = timeout <= 0
+		}
+		deadlineFirst := false
+		if !deadline.IsZero() {
+			remaining := deadline.Sub(r.now())
+			if remaining <= 0 {
+				return 0, errTimeout
+			}
+			if timeout == 0 || remaining < timeout {
+				timeout = remaining
+				deadlineFirst = true
+			}
+		}
+		settleFirst := false
+		if settle > 0 && (timeout == 0 || settle < timeout) {
+			// check again once the stability window has passed
+			timeout = settle
+			deadlineFirst = false
+			settleFirst = true
+		}
+		stallFirst := false
+		if stall := r.untilStall(); stall > 0 && (timeout == 0 || stall < timeout) {
+			// report the stall while continuing to wait
+			timeout = stall
+			deadlineFirst = false
+			settleFirst = false
+			stallFirst = true
+		}
+
+		err, event := errTimeout, fsnotify.Op(0)
+		if idleExpired {
+			settleFirst, stallFirst, deadlineFirst = false, false, false
+		} else {
+			err, event = r.waitForEventWithTimeout(r.eventMask(), timeout)
+		}
 
 		if errors.Is(err, errTimeout) {
-			if r.options.TreatTimeoutsAsEOF {
+			if settleFirst || stallFirst {
+				continue
+			}
+			if deadlineFirst {
+				return 0, errTimeout
+			}
+			switch r.idleAction(r.now().Sub(waitingSince)) {
+			case IdleContinue:
+				idleSince = r.now()
+				continue
+			case IdleReturnEOF:
 				return 0, io.EOF
 			}
 			err = ErrIdleTimeout
@@ -213,33 +1097,197 @@ func (r *TailingReader) Read(p []byte) (n int, err error) {
 			return 0, err
 		}
 
+		reopened = false
+		r.verifyPending = r.options.DetectModification
+
 		if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
 			if r.options.CloseOnDelete {
 				return 0, io.EOF
 			}
-			_ = r.closeFile()
+			if !r.detach() {
+				_ = r.restartFile()
+			}
 		}
 	}
 }
 
+func (r *TailingReader) advance(data []byte) {
+	r.offset += int64(len(data))
+	r.remember(data)
+	r.recordData()
+	if r.options.Fadvise && r.osFile != nil {
+		r.adviseConsumed()
+	}
+}
+
+func (r *TailingReader) readFile(p []byte, size int64) (int, error) {
+	if r.osFile == nil {
+		return r.file.Read(p)
+	}
+
+	if r.options.SkipHoles {
+		err := r.skipHole()
+		if err != nil {
+			// returns io.EOF if there's only a hole left up to the end of the file
+			return 0, err
+		}
+	}
+
+	if r.direct {
+		return r.readDirect(p)
+	}
+
+	if r.mmap == nil && r.options.MmapThreshold > 0 && size-r.offset >= r.options.MmapThreshold {
+		// catching up on a larger amount of existing data; fall back to
+		// normal reads if the file cannot be mapped
+		_ = r.mapFile(size)
+	}
+
+	if r.mmap != nil {
+		if r.offset < r.mmapOffset+int64(len(r.mmap)) {
+			n, err := r.readMmap(p)
+			if err == nil {
+				return n, nil
+			}
+		}
+
+		// caught up (or the mapping became invalid), continue with normal reads
+		err := r.unmapFile()
+		if err != nil {
+			return 0, err
+		}
+		_, err = r.osFile.Seek(r.offset, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return r.file.Read(p)
+}
+
+// waitForEventWithTimeout waits for an event of the given type on the file
+//
+// It must be called with r.mu held. The lock is released while waiting, so
+// methods like Seek or SetFilePath can be called from other goroutines; these
+// wake up the wait, which then returns without an error and event.
 func (r *TailingReader) waitForEventWithTimeout(eventType fsnotify.Op, timeout time.Duration) (error, fsnotify.Op) {
+	r.updateWatch()
+	r.watchTarget()
+
+	filePath := r.eventPath(r.filePath)
+	targetPath := ""
+	if r.target != "" {
+		targetPath = r.eventPath(r.target)
+	}
+	ignoreChmod := r.options.IgnoreChmod
+
 	var c <-chan time.Time
 	if timeout > 0 {
-		timer := time.NewTimer(timeout)
-		c = timer.C
+		timer := r.clock().NewTimer(timeout)
+		defer timer.Stop()
+		c = timer.C()
+	}
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if r.watcher != nil {
+		events = r.watcher.Events
+		errs = r.watcher.Errors
+	}
+
+	// the link count isn't changed by events for our path, see DetectUnlink
+	checkUnlink := r.options.DetectUnlink && !r.detached && r.osFile != nil
+
+	var pollTimer Timer
+	var poll <-chan time.Time
+	if r.polling || r.detached || checkUnlink {
+		pollTimer = r.clock().NewTimer(r.pollInterval())
+		defer pollTimer.Stop()
+		poll = pollTimer.C()
 	}
 
+	r.mu.Unlock()
+	defer r.mu.Lock()
+
 	for {
 		select {
-		case event := <-r.watcher.Events:
-			if eventType&event.Op == event.Op && event.Name == r.filePath {
+		case event, ok := <-events:
+			if !ok {
+				return r.watcherClosed(), 0
+			}
+			name := r.eventPath(event.Name)
+			if eventType&event.Op == event.Op && (name == filePath || name == targetPath || event.Op == fsnotify.Create && r.isCandidate(event.Name)) {
+				if event.Op == fsnotify.Chmod && ignoreChmod && !r.truncated() {
+					continue
+				}
 				//fmt.Fprintf(os.Stdout, "event: %v -- file: %s\n", event.Op, event.Name)
 				return nil, event.Op
 			}
-		case err := <-r.watcher.Errors:
+		case err, ok := <-errs:
+			if !ok {
+				return r.watcherClosed(), 0
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// events were lost; have the caller check the file's state
+				return nil, 0
+			}
 			return err, 0
+		case <-poll:
+			r.mu.Lock()
+			var op fsnotify.Op
+			if r.polling || r.detached {
+				op = r.pollFile()
+			}
+			unlinked := op == 0 && checkUnlink && r.unlinked()
+			interval := r.pollInterval()
+			r.mu.Unlock()
+
+			if unlinked {
+				return nil, fsnotify.Remove
+			}
+
+			if op != 0 && eventType&op == op {
+				return nil, op
+			}
+			pollTimer.Reset(interval)
 		case <-c:
 			return errTimeout, 0
+		case <-r.closed:
+			return ErrClosed, 0
+		case <-r.wakeup:
+			return nil, 0
 		}
 	}
 }
+
+// watcherClosed returns the error for a watcher that stopped delivering events
+func (r *TailingReader) watcherClosed() error {
+	if r.isClosed() {
+		return ErrClosed
+	}
+	return ErrWatcherFailed
+}
+
+func (r *TailingReader) eventMask() fsnotify.Op {
+	if r.options.EventMask != 0 {
+		return r.options.EventMask
+	}
+	return DefaultEventMask
+}
+
+// truncated checks whether the file is smaller than the current offset
+func (r *TailingReader) truncated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := r.statFile()
+	return err == nil && info.Size() < r.offset
+}
+
+// wake wakes up a pending wait for events
+func (r *TailingReader) wake() {
+	select {
+	case r.wakeup <- struct{}{}:
+	default:
+	}
+}
@@ -1,11 +1,27 @@
 package tailreader
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/maurice2k/tailreader/tailtest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,6 +43,122 @@ func TestNewTailingReaderWithOptions(t *testing.T) {
 	assert.True(t, tr.options.CloseOnTruncate)
 }
 
+func TestNewTailingReaderWithInvalidOptions(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	for _, options := range [][]Option{
+		{WithIdleTimeout(-1 * time.Second)},
+		{WithWaitForFile(true, -1*time.Second)},
+		{WithWaitForFile(false, time.Second)},
+		{WithWaitForFile(true, 0), WithCloseOnDelete(true)},
+		{WithReadahead(-1)},
+	} {
+		_, err := NewTailingReader(file.Name(), options...)
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	}
+}
+
+func TestNewTailingReaderWithConfigFromEnv(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	t.Setenv("TAILREADER_WAIT_FOR_FILE_TIMEOUT", "30s")
+	t.Setenv("TAILREADER_CLOSE_ON_DELETE", "true")
+	t.Setenv("TAILREADER_IDLE_TIMEOUT", "1m")
+
+	cfg, err := ConfigFromEnv("TAILREADER_")
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReaderWithConfig(file.Name(), cfg)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	assert.True(t, tr.options.WaitForFile)
+	assert.Equal(t, 30*time.Second, tr.options.WaitForFileTimeout)
+	assert.True(t, tr.options.CloseOnDelete)
+	assert.Equal(t, time.Minute, tr.options.IdleTimeout)
+
+	t.Setenv("TAILREADER_IDLE_TIMEOUT", "forever")
+	_, err = ConfigFromEnv("TAILREADER_")
+	assert.Error(t, err)
+}
+
+func TestConfigFromMap(t *testing.T) {
+	cfg, err := ConfigFromMap(map[string]string{
+		"idle_timeout":    "1m",
+		"CLOSE_ON_DELETE": "true",
+	})
+	assert.NoError(t, err)
+	assert.True(t, cfg.WaitForFile)
+	assert.Equal(t, time.Minute, cfg.IdleTimeout)
+	assert.True(t, cfg.CloseOnDelete)
+
+	_, err = ConfigFromMap(map[string]string{"idle_timeout": "forever"})
+	assert.Error(t, err)
+
+	_, err = ConfigFromMap(map[string]string{"idle_timeuot": "1m"})
+	assert.Error(t, err)
+}
+
+func TestNewTailingReaderFromFile(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("skipped")
+	assert.NoError(t, err)
+
+	f, err := os.Open(file.Name())
+	assert.NoError(t, err)
+	_, err = f.Seek(0, io.SeekEnd)
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReaderFromFile(f)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err = file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+func TestNewTailingReaderFromFileWithStartAtEnd(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("skipped")
+	assert.NoError(t, err)
+
+	// the configured start position takes precedence over the file's position
+	f, err := os.Open(file.Name())
+	assert.NoError(t, err)
+	tr, err := NewTailingReaderFromFile(f, WithStartAtEnd(true), WithStatsInterval(time.Millisecond, func(Stats) {}))
+	assert.NoError(t, err)
+	defer tr.Close()
+	assert.Equal(t, int64(7), tr.Offset())
+
+	str := "Hello, World!"
+	_, err = file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+
+	// the file is closed if the reader can't be created
+	f, err = os.Open(file.Name())
+	assert.NoError(t, err)
+	_, err = NewTailingReaderFromFile(f, WithChecksum("md4"))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	assert.ErrorIs(t, f.Close(), os.ErrClosed)
+}
+
 func TestTailingReader_Read(t *testing.T) {
 	file, _ := os.CreateTemp("", "test")
 	defer os.Remove(file.Name())
@@ -45,6 +177,26 @@ func TestTailingReader_Read(t *testing.T) {
 	assert.Equal(t, str, string(buf[:n]))
 }
 
+func TestTailingReader_ReadWithStartAtEnd(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("skipped")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithStartAtEnd(true))
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err = file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
 func TestTailingReader_ReadAfterFileTruncatedWithCloseOnTruncate(t *testing.T) {
 	file, _ := os.CreateTemp("", "test")
 	defer os.Remove(file.Name())
@@ -96,10 +248,43 @@ func TestTailingReader_ReadAfterFileTruncatedWithoutCloseOnTruncate(t *testing.T
 	assert.Equal(t, str, string(buf[:n]))
 }
 
+func TestTailingReader_ReadAfterFileTruncatedWithSeekOnTruncate(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	var truncatedAt []int64
+	tr, _ := NewTailingReader(file.Name(), WithSeekOnTruncate(true), WithOnTruncate(func(offset int64) {
+		truncatedAt = append(truncatedAt, offset)
+	}))
+	defer tr.Close()
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+	opened := tr.osFile
+
+	assert.NoError(t, file.Truncate(0))
+	_, err = file.WriteAt([]byte("Hello"), 0)
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	// the descriptor is kept
+	assert.Same(t, opened, tr.osFile)
+	assert.Equal(t, []int64{13}, truncatedAt)
+	assert.Equal(t, int64(1), tr.Generation())
+}
+
 func TestTailingReader_ReadAfterFileDeleted(t *testing.T) {
 	file, _ := os.CreateTemp("", "test")
 
-	tr, _ := NewTailingReader(file.Name(), WithCloseOnDelete(true), WithWaitForFile(true, 0))
+	tr, _ := NewTailingReader(file.Name(), WithCloseOnDelete(true), WithWaitForFile(true, time.Minute))
 	defer tr.Close()
 
 	str := "Hello, World!"
@@ -152,3 +337,3567 @@ func TestTailingReader_Close(t *testing.T) {
 	assert.Nil(t, tr.file)
 	assert.Nil(t, tr.watcher)
 }
+
+func TestTailingReader_ReadWithMmap(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithMmap(1024))
+	defer tr.Close()
+
+	data := make([]byte, 64*1024)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	_, err := file.Write(data)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 4096)
+	var read []byte
+	for len(read) < len(data) {
+		n, err := tr.Read(buf)
+		assert.NoError(t, err)
+		read = append(read, buf[:n]...)
+	}
+	assert.Equal(t, data, read)
+
+	// live data is read without the mapping
+	str := "Hello, World!"
+	_, err = file.WriteString(str)
+	assert.NoError(t, err)
+
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+	assert.Nil(t, tr.mmap)
+}
+
+func TestTailingReader_ReadWithFadvise(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithFadvise(true))
+	defer tr.Close()
+
+	data := make([]byte, 2*1024*1024)
+	_, err := file.Write(data)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 64*1024)
+	read := 0
+	for read < len(data) {
+		n, err := tr.Read(buf)
+		assert.NoError(t, err)
+		read += n
+	}
+	assert.Equal(t, len(data), read)
+}
+
+func TestTailingReader_ReadWithNoWaitRead(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithNoWaitRead(true))
+	defer tr.Close()
+
+	for _, str := range []string{"Hello, World!", "Hello, World again!"} {
+		_, err := file.WriteString(str)
+		assert.NoError(t, err)
+
+		buf := make([]byte, 128)
+		n, err := tr.Read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, str, string(buf[:n]))
+	}
+}
+
+func TestTailingReader_ReadWithDirectIO(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithDirectIO(true))
+	defer tr.Close()
+
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	_, err := file.Write(data)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 3000)
+	var read []byte
+	for len(read) < len(data) {
+		n, err := tr.Read(buf)
+		if len(read) == 0 && err != nil {
+			t.Skipf("direct I/O not supported: %v", err)
+		}
+		assert.NoError(t, err)
+		read = append(read, buf[:n]...)
+	}
+	assert.Equal(t, data, read)
+}
+
+func TestTailingReader_ReadWithSkipHoles(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithSkipHoles(true))
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteAt([]byte(str), 1024*1024)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	if n == len(buf) {
+		t.Skip("file system doesn't support SEEK_DATA")
+	}
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithReadahead(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithReadahead(64))
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+	assert.Equal(t, int64(len(str)), tr.offset)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", Wor", string(buf[:n]))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ld!", string(buf[:n]))
+
+	str = "Hello, World again!"
+	_, err = file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf = make([]byte, 128)
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+func TestTailingReader_CloseTwice(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	assert.NoError(t, tr.Close())
+	assert.NoError(t, tr.Close())
+}
+
+func TestTailingReader_ReadAfterClose(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	_ = tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestTailingReader_CloseWhileReading(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = tr.Close()
+	}()
+
+	// nothing to read; blocks until the reader is closed
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestTailingReader_Reopen(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithReadahead(64))
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	err = tr.Reopen(true)
+	assert.NoError(t, err)
+
+	buf = make([]byte, 128)
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", World!", string(buf[:n]))
+
+	err = tr.Reopen(false)
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+func TestTailingReader_ReopenIfReplaced(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+	defer os.Remove(file.Name() + ".1")
+
+	_, err := file.WriteString("Hello")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithFollowMode(FollowDescriptor))
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	// the same file isn't reopened
+	reopened, err := tr.ReopenIfReplaced()
+	assert.NoError(t, err)
+	assert.False(t, reopened)
+
+	// a replacement is read from its beginning, even if it's larger than the offset
+	assert.NoError(t, os.Rename(file.Name(), file.Name()+".1"))
+	assert.NoError(t, os.WriteFile(file.Name(), []byte("Hello, World!"), 0644))
+
+	reopened, err = tr.ReopenIfReplaced()
+	assert.NoError(t, err)
+	assert.True(t, reopened)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_Offset(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithReadahead(64))
+	defer tr.Close()
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	_, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), tr.Offset())
+}
+
+func TestTailingReader_SaveCheckpoint(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+
+	tr, _ := NewTailingReader(file.Name(), WithCheckpointStore(store))
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	_, err = tr.Read(buf)
+	assert.NoError(t, err)
+
+	err = tr.SaveCheckpoint()
+	assert.NoError(t, err)
+	_ = tr.Close()
+
+	// a new reader resumes at the checkpoint
+	tr, _ = NewTailingReader(file.Name(), WithCheckpointStore(store))
+	defer tr.Close()
+
+	buf = make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", World!", string(buf[:n]))
+}
+
+func TestTailingReader_SaveCheckpointFileReplaced(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file identities aren't saved on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	assert.NoError(t, os.WriteFile(path, []byte("Hello, World!"), 0644))
+
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+
+	tr, _ := NewTailingReader(path, WithCheckpointStore(store))
+	buf := make([]byte, 128)
+	_, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.NoError(t, tr.SaveCheckpoint())
+	_ = tr.Close()
+
+	// the file is rotated; the new one is read from the start instead of at the stale offset
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, os.WriteFile(path, []byte("Hello again, World!"), 0644))
+
+	tr, _ = NewTailingReader(path, WithCheckpointStore(store))
+	defer tr.Close()
+
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello again, World!", string(buf[:n]))
+}
+
+func TestTailingReader_Seek(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	offset, err := tr.Seek(-6, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), offset)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(buf[:n]))
+
+	_, err = tr.Seek(1, io.SeekCurrent)
+	assert.Error(t, err)
+
+	// seeking wakes up a pending read
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = tr.Seek(0, io.SeekStart)
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+func TestTailingReader_Reset(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+
+	err = tr.Reset()
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+func TestTailingReader_SetFilePath(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	file2, _ := os.CreateTemp(t.TempDir(), "test")
+	defer os.Remove(file2.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+
+	err = tr.SetFilePath(file2.Name())
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = file.WriteString("ignored")
+		_, _ = file2.WriteString("Hello, other World!")
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, other World!", string(buf[:n]))
+}
+
+func TestTailingReader_UpdateOptions(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+
+	err = tr.UpdateOptions(WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	assert.NoError(t, err)
+
+	// nothing to read; the updated idle timeout applies
+	n, err = tr.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 100*time.Millisecond, tr.options.IdleTimeout)
+}
+
+func TestTailingReaderFS_Read(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	tr, err := NewTailingReaderFS(fsys, "test.log", WithWaitForFile(false, 0), WithPollInterval(10*time.Millisecond), WithIdleTimeout(100*time.Millisecond))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	str := "Hello, World!"
+	fsys["test.log"] = &fstest.MapFile{Data: []byte(str)}
+
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+
+	fsys["test.log"].Data = append(fsys["test.log"].Data, " Hello, World again!"...)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " Hello, World again!", string(buf[:n]))
+
+	// nothing to read; polling doesn't detect any changes
+	n, err = tr.Read(buf)
+	assert.Equal(t, ErrIdleTimeout, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestTailingReader_ReadWithOpenFunc(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithOpenFunc(func(name string, flag int) (fs.File, error) {
+		return nil, os.ErrPermission
+	}))
+	defer tr.Close()
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, 0, n)
+}
+
+func TestTailingReader_ReadWithOpenFuncOSFile(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	var flags []int
+	tr, err := NewTailingReader(file.Name(), WithMmap(1), WithOpenFunc(func(name string, flag int) (fs.File, error) {
+		flags = append(flags, flag)
+		return os.OpenFile(name, flag|os.O_SYNC, 0)
+	}))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+	assert.Equal(t, []int{os.O_RDONLY}, flags)
+	assert.NotNil(t, tr.osFile)
+}
+
+func TestTailingReader_ReadWithStatFunc(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	// the file vanishes for the first stat
+	vanished := false
+	tr, _ := NewTailingReader(file.Name(), WithWaitForFile(false, 0), WithStatFunc(func(name string) (fs.FileInfo, error) {
+		if !vanished {
+			vanished = true
+			return nil, os.ErrNotExist
+		}
+		return os.Stat(name)
+	}))
+	defer tr.Close()
+
+	str := "Hello, World!"
+	_, err := file.WriteString(str)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, 0, n)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, str, string(buf[:n]))
+}
+
+// memFS is an in-memory FS; files are replaced on every change so open files are stable snapshots
+type memFS struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+func newMemFS() *memFS {
+	return &memFS{files: fstest.MapFS{}}
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Stat(name)
+}
+
+func (m *memFS) OpenFile(name string, flag int) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Open(name)
+}
+
+func (m *memFS) WriteString(name string, str string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var data []byte
+	if f, ok := m.files[name]; ok {
+		data = append(data, f.Data...)
+	}
+	m.files[name] = &fstest.MapFile{Data: append(data, str...), ModTime: time.Now()}
+}
+
+func (m *memFS) Truncate(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &fstest.MapFile{ModTime: time.Now()}
+}
+
+func (m *memFS) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, name)
+}
+
+func TestTailingReader_ReadWithFS(t *testing.T) {
+	fsys := newMemFS()
+	fsys.WriteString("test.log", "")
+
+	tr, err := NewTailingReader("test.log", WithFS(fsys), WithPollInterval(10*time.Millisecond), WithCloseOnDelete(true), WithWaitForFile(true, time.Second))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fsys.WriteString("test.log", "Hello, World!")
+	}()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fsys.Truncate("test.log")
+		fsys.WriteString("test.log", "Hello again!")
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello again!", string(buf[:n]))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fsys.Remove("test.log")
+	}()
+
+	n, err = tr.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+}
+
+// fakeClock is a Clock whose time only moves on Advance
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), deadline: c.now.Add(d), active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the time forward as soon as a timer is waiting and fires all expired timers
+func (c *fakeClock) Advance(d time.Duration) {
+	for !c.hasActiveTimer() {
+		time.Sleep(time.Millisecond)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		if t.active && !t.deadline.After(c.now) {
+			t.active = false
+			t.c <- c.now
+		}
+	}
+}
+
+func (c *fakeClock) hasActiveTimer() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, t := range c.timers {
+		if t.active {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.active
+	t.active = true
+	t.deadline = t.clock.now.Add(d)
+	return active
+}
+
+func TestTailingReader_ReadWithIdleTimeoutAndClock(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	clock := &fakeClock{}
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(time.Hour), WithClock(clock))
+	defer tr.Close()
+
+	go clock.Advance(time.Hour)
+
+	// nothing to read; the idle timeout is triggered by the clock
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.Equal(t, ErrIdleTimeout, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestTailingReader_ReadWithStatsInterval(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("a\nb\nc")
+	assert.NoError(t, err)
+
+	reports := make(chan Stats, 1)
+	clock := &fakeClock{}
+	tr, err := NewTailingReader(file.Name(), WithClock(clock), WithStatsInterval(time.Minute, func(stats Stats) {
+		reports <- stats
+	}))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 4)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(buf[:n]))
+
+	clock.Advance(time.Minute)
+	stats := <-reports
+	assert.Equal(t, time.Minute, stats.Interval)
+	assert.Equal(t, int64(4), stats.IntervalBytes)
+	assert.Equal(t, int64(2), stats.IntervalRecords)
+	assert.Equal(t, int64(1), stats.Lag)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "c", string(buf[:n]))
+
+	clock.Advance(time.Minute)
+	stats = <-reports
+	assert.Equal(t, int64(1), stats.IntervalBytes)
+	assert.Equal(t, int64(0), stats.IntervalRecords)
+	assert.Equal(t, int64(0), stats.Lag)
+	assert.Equal(t, int64(5), stats.Delivered)
+	assert.Equal(t, int64(2), stats.Records)
+
+	_, err = NewTailingReader(file.Name(), WithStatsInterval(-time.Second, nil))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestRecordReader_ReadRecord(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	_, err := file.WriteString("Hello, World!\nHello again!\n0123456789\nincomplete")
+	assert.NoError(t, err)
+
+	rr := NewRecordReader(tr, WithMaxRecordSize(5))
+
+	var records []string
+	var offsets []int64
+	for {
+		rec, err := rr.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, file.Name(), rec.Path)
+		records = append(records, string(rec.Data))
+		offsets = append(offsets, rec.Offset)
+	}
+
+	assert.Equal(t, []string{"Hello", ", Wor", "ld!", "Hello", " agai", "n!", "01234", "56789", "incom", "plete"}, records)
+	assert.Equal(t, []int64{0, 5, 10, 14, 19, 24, 27, 32, 38, 43}, offsets)
+}
+
+func TestFilterReader_ReadRecord(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	_, err := file.WriteString("info: starting\nerror: failed\ninfo: retrying\nerror: gave up")
+	assert.NoError(t, err)
+
+	readAll := func(src RecordSource) []string {
+		var records []string
+		for {
+			rec, err := src.ReadRecord()
+			if err == io.EOF {
+				return records
+			}
+			assert.NoError(t, err)
+			records = append(records, string(rec.Data))
+		}
+	}
+
+	re := regexp.MustCompile(`^error:.*$`)
+	assert.Equal(t, []string{"error: failed", "error: gave up"}, readAll(NewFilterReader(NewRecordReader(tr), re, false)))
+
+	_, err = tr.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"info: starting", "info: retrying"}, readAll(NewFilterReader(NewRecordReader(tr), re, true)))
+}
+
+func TestLevelFilterReader_ReadRecord(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	_, err := file.WriteString("level=debug msg=starting\nlevel=WARNING msg=slow\n\tat main.go:12\nlevel=info msg=done\nlevel=error msg=failed\n")
+	assert.NoError(t, err)
+
+	extractor, err := NewRegexpLevelExtractor(`level=(\w+)`)
+	assert.NoError(t, err)
+
+	var records []Record
+	src := NewLevelFilterReader(NewRecordReader(tr, WithLevelExtractor(extractor)), LevelWarn)
+	for {
+		rec, err := src.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		records = append(records, rec)
+	}
+
+	// records without a level are passed
+	assert.Len(t, records, 3)
+	assert.Equal(t, LevelWarn, records[0].Level)
+	assert.Equal(t, LevelNone, records[1].Level)
+	assert.Equal(t, "\tat main.go:12", string(records[1].Data))
+	assert.Equal(t, LevelError, records[2].Level)
+	assert.Equal(t, "ERROR", records[2].Level.String())
+
+	json := &JSONLevelExtractor{Field: "severity"}
+	level, ok := json.ExtractLevel([]byte(`{"severity": "Critical", "msg": "down"}`))
+	assert.True(t, ok)
+	assert.Equal(t, LevelFatal, level)
+
+	_, ok = json.ExtractLevel([]byte(`{"severity": 3}`))
+	assert.False(t, ok)
+	_, ok = json.ExtractLevel([]byte(`severity=error`))
+	assert.False(t, ok)
+}
+
+func TestSampleReader_ReadRecord(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	_, err := file.WriteString("1\n2\n3\n4\n5\n6\n7\n")
+	assert.NoError(t, err)
+
+	readAll := func(src RecordSource) []string {
+		var records []string
+		for {
+			rec, err := src.ReadRecord()
+			if err == io.EOF {
+				return records
+			}
+			assert.NoError(t, err)
+			records = append(records, string(rec.Data))
+		}
+	}
+
+	sr := NewSampleReader(NewRecordReader(tr), 3)
+	assert.Equal(t, []string{"1", "4", "7"}, readAll(sr))
+	assert.Equal(t, uint64(4), sr.Dropped())
+
+	_, err = tr.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	sr = NewRandomSampleReader(NewRecordReader(tr), 0)
+	assert.Empty(t, readAll(sr))
+	assert.Equal(t, uint64(7), sr.Dropped())
+
+	_, err = tr.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	sr = NewRandomSampleReader(NewRecordReader(tr), 1)
+	assert.Len(t, readAll(sr), 7)
+	assert.Equal(t, uint64(0), sr.Dropped())
+}
+
+func TestDedupReader_ReadRecord(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	_, err := file.WriteString("a\na\na\nb\n\n\nc\nc\n")
+	assert.NoError(t, err)
+
+	dr := NewDedupReader(NewRecordReader(tr), 0)
+
+	var records []string
+	var offsets []int64
+	for {
+		rec, err := dr.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		records = append(records, string(rec.Data))
+		offsets = append(offsets, rec.Offset)
+	}
+
+	assert.Equal(t, []string{"a", "last message repeated 2 times", "b", "", "last message repeated 1 times", "c", "last message repeated 1 times"}, records)
+	assert.Equal(t, []int64{0, 4, 6, 8, 9, 10, 12}, offsets)
+}
+
+func TestDedupReader_ReadRecordWithWindow(t *testing.T) {
+	start := time.Now()
+	src := &sliceSource{records: []Record{
+		{Data: []byte("a"), Time: start},
+		{Data: []byte("a"), Time: start.Add(time.Second)},
+		{Data: []byte("a"), Time: start.Add(3 * time.Second)},
+		{Data: []byte("a"), Time: start.Add(4 * time.Second)},
+	}}
+
+	dr := NewDedupReader(src, 2*time.Second)
+
+	var records []string
+	for {
+		rec, err := dr.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		records = append(records, string(rec.Data))
+	}
+
+	assert.Equal(t, []string{"a", "last message repeated 1 times", "a", "last message repeated 1 times"}, records)
+}
+
+// sliceSource is a RecordSource returning the given records, then io.EOF
+type sliceSource struct {
+	records []Record
+}
+
+func (s *sliceSource) ReadRecord() (Record, error) {
+	if len(s.records) == 0 {
+		return Record{}, io.EOF
+	}
+	rec := s.records[0]
+	s.records = s.records[1:]
+	return rec, nil
+}
+
+func TestTailingReader_AsPipe(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	pipe := tr.AsPipe(context.Background())
+	defer pipe.Close()
+
+	data, err := io.ReadAll(pipe)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(data))
+}
+
+func TestTailingReader_AsPipeCancel(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	ctx, cancel := context.WithCancel(context.Background())
+	pipe := tr.AsPipe(ctx)
+	defer pipe.Close()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := io.ReadAll(pipe)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = tr.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// failingWriter accepts up to limit bytes
+type failingWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n, _ := w.Buffer.Write(p[:w.limit])
+		w.limit = 0
+		return n, errors.New("write failed")
+	}
+	w.limit -= len(p)
+	return w.Buffer.Write(p)
+}
+
+func TestTeeReader_Read(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	archive := &failingWriter{limit: 5}
+	tee := NewTeeReader(tr, archive)
+	defer tee.Close()
+
+	buf := make([]byte, 128)
+	n, err := tee.Read(buf)
+	assert.Error(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+	assert.Equal(t, int64(5), tr.Offset())
+
+	archive.limit = 100
+	n, err = tee.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", World!", string(buf[:n]))
+	assert.Equal(t, "Hello, World!", archive.String())
+
+	_, err = tee.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTeeReader_ReadWithPrefetch(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("0123456789")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithPrefetch(true), WithChecksum(ChecksumSHA256),
+		WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	archive := &failingWriter{limit: 2}
+	tee := NewTeeReader(tr, archive)
+	defer tee.Close()
+
+	buf := make([]byte, 128)
+	n, err := tee.Read(buf)
+	assert.Error(t, err)
+	assert.Equal(t, "01", string(buf[:n]))
+	assert.Equal(t, int64(2), tr.Stats().Delivered)
+
+	// the data not written is delivered again before the prefetched data
+	archive.limit = 100
+	data, err := io.ReadAll(tee)
+	assert.NoError(t, err)
+	assert.Equal(t, "23456789", string(data))
+	assert.Equal(t, "0123456789", archive.String())
+
+	// and counted once
+	sum := sha256.Sum256([]byte("0123456789"))
+	stats := tr.Stats()
+	assert.Equal(t, hex.EncodeToString(sum[:]), stats.Checksum)
+	assert.Equal(t, int64(10), stats.Delivered)
+	assert.Equal(t, int64(10), stats.Offset)
+}
+
+func TestTailingReader_ReadWithRateLimit(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	clock := &fakeClock{}
+	tr, _ := NewTailingReader(file.Name(), WithRateLimit(5), WithClock(clock))
+	defer tr.Close()
+
+	// a burst of one second's worth of data, then reads wait for the bucket to refill
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", Wor", string(buf[:n]))
+
+	go clock.Advance(time.Second)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ld!", string(buf[:n]))
+	assert.Equal(t, time.Second, clock.Now().Sub(time.Time{}))
+}
+
+func TestTailingReader_Chunks(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	stream := tr.Chunks(context.Background())
+
+	var data []byte
+	for chunk := range stream.C() {
+		assert.Equal(t, int64(len(data)), chunk.Offset)
+		data = append(data, chunk.Data...)
+	}
+
+	assert.Equal(t, "Hello, World!", string(data))
+	assert.NoError(t, stream.Err())
+}
+
+func TestTailingReader_ChunksOverflow(t *testing.T) {
+	for _, policy := range []OverflowPolicy{DropOldest, DropNewest} {
+		file, _ := os.CreateTemp("", "test")
+		defer os.Remove(file.Name())
+
+		tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+		defer tr.Close()
+
+		stream := tr.Chunks(context.Background(), WithQueueSize(1), WithOverflowPolicy(policy))
+
+		for _, s := range []string{"one", "two", "three"} {
+			_, err := file.WriteString(s)
+			assert.NoError(t, err)
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		var chunks []string
+		for chunk := range stream.C() {
+			chunks = append(chunks, string(chunk.Data))
+		}
+
+		if policy == DropOldest {
+			assert.Equal(t, []string{"three"}, chunks)
+		} else {
+			assert.Equal(t, []string{"one"}, chunks)
+		}
+		assert.Equal(t, uint64(2), stream.Dropped())
+	}
+}
+
+func TestTailingReader_ChunksCancel(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name())
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := tr.Chunks(ctx)
+
+	cancel()
+	for range stream.C() {
+	}
+
+	assert.ErrorIs(t, stream.Err(), context.Canceled)
+}
+
+func TestTailingReader_ReadBatch(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(time.Second), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	go func() {
+		for _, s := range []string{"one", "two", "three", "four"} {
+			_, _ = file.WriteString(s)
+			time.Sleep(30 * time.Millisecond)
+		}
+	}()
+
+	// the batch is limited by max
+	batch, err := tr.ReadBatch(2, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("one"), []byte("two")}, batch)
+
+	// the batch is limited by the wait window
+	batch, err = tr.ReadBatch(10, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("three")}, batch)
+
+	// the idle timeout ends the batch; io.EOF is returned by the next call
+	batch, err = tr.ReadBatch(10, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("four")}, batch)
+
+	batch, err = tr.ReadBatch(10, 5*time.Second)
+	assert.Equal(t, io.EOF, err)
+	assert.Nil(t, batch)
+}
+
+func TestRecordReader_ReadRecordBatch(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("one\ntwo\nthree\nfou")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(time.Second), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+	rr := NewRecordReader(tr)
+
+	records, err := rr.ReadRecordBatch(2, time.Second)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "two", string(records[1].Data))
+
+	// the incomplete line is not returned before the window closes
+	records, err = rr.ReadRecordBatch(10, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "three", string(records[0].Data))
+
+	_, err = file.WriteString("r\n")
+	assert.NoError(t, err)
+
+	records, err = rr.ReadRecordBatch(10, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "four", string(records[0].Data))
+}
+
+func TestBroadcaster(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(200*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	b := NewBroadcaster(tr)
+	archive := b.Subscribe()
+	ui := b.Subscribe(WithQueueSize(1), WithOverflowPolicy(DropNewest))
+	gone := b.Subscribe()
+	b.Unsubscribe(gone)
+
+	_, ok := <-gone.C()
+	assert.False(t, ok)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- b.Run(context.Background())
+	}()
+
+	for _, s := range []string{"one", "two", "three"} {
+		_, err := file.WriteString(s)
+		assert.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var data []byte
+	for chunk := range archive.C() {
+		data = append(data, chunk.Data...)
+	}
+	assert.Equal(t, "onetwothree", string(data))
+	assert.NoError(t, <-errc)
+
+	// the UI subscriber never read, so it only got the first chunk
+	var chunks []string
+	for chunk := range ui.C() {
+		chunks = append(chunks, string(chunk.Data))
+	}
+	assert.Equal(t, []string{"one"}, chunks)
+	assert.Equal(t, uint64(2), ui.Dropped())
+
+	// subscribing after the end returns a closed stream
+	_, ok = <-b.Subscribe().C()
+	assert.False(t, ok)
+}
+
+func TestTailingReader_Clone(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithReadahead(64), WithIdleTimeout(100*time.Millisecond), WithTimeoutsAsEOF(true))
+	defer tr.Close()
+
+	buf := make([]byte, 7)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, ", string(buf[:n]))
+
+	// the file is rotated; the clone follows the original file
+	assert.NoError(t, os.Rename(file.Name(), file.Name()+".1"))
+	defer os.Remove(file.Name() + ".1")
+	assert.NoError(t, os.WriteFile(file.Name(), []byte("new file"), 0644))
+
+	clone, err := tr.Clone()
+	if runtime.GOOS != "linux" {
+		assert.Error(t, err)
+		return
+	}
+	assert.NoError(t, err)
+	defer clone.Close()
+
+	assert.Equal(t, int64(7), clone.Offset())
+
+	data, err := io.ReadAll(io.LimitReader(clone, 6))
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(data))
+
+	// both readers are independent
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(buf[:n]))
+}
+
+func TestTailingReader_CloneWithLockFile(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	lockFile := file.Name() + ".lock"
+	defer os.Remove(lockFile)
+
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+	tr, _ := NewTailingReader(file.Name(), WithLockFile(lockFile), WithCheckpointStore(store), WithPollInterval(10*time.Millisecond))
+	defer tr.Close()
+
+	buf := make([]byte, 7)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, ", string(buf[:n]))
+
+	clone, err := tr.Clone()
+	assert.NoError(t, err)
+	defer clone.Close()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(buf[:n]))
+	assert.NoError(t, tr.SaveCheckpoint())
+
+	// the clone neither waits for the lock held by tr nor resumes at its checkpoint
+	start := time.Now()
+	n, err = clone.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(buf[:n]))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestTailingReader_ReadWithLockFile(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	lockFile := file.Name() + ".lock"
+	defer os.Remove(lockFile)
+
+	active, _ := NewTailingReader(file.Name(), WithLockFile(lockFile), WithPollInterval(10*time.Millisecond))
+	standby, _ := NewTailingReader(file.Name(), WithLockFile(lockFile), WithPollInterval(10*time.Millisecond))
+	defer standby.Close()
+
+	buf := make([]byte, 128)
+	n, err := active.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+
+	// the standby waits until the active reader releases the lock
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = active.Close()
+	}()
+
+	start := time.Now()
+	n, err = standby.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestTailingReader_LockFileHandoff(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("one\ntwo\n")
+	assert.NoError(t, err)
+
+	lockFile := file.Name() + ".lock"
+	defer os.Remove(lockFile)
+	store := NewFileCheckpointStore(file.Name() + ".checkpoint")
+	defer os.Remove(file.Name() + ".checkpoint")
+
+	options := []Option{WithLockFile(lockFile), WithCheckpointStore(store), WithPollInterval(10 * time.Millisecond)}
+	active, _ := NewTailingReader(file.Name(), options...)
+	standby, _ := NewTailingReader(file.Name(), options...)
+	defer standby.Close()
+
+	buf := make([]byte, 4)
+	n, err := active.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "one\n", string(buf[:n]))
+	assert.NoError(t, active.SaveCheckpoint())
+
+	// the active reader dies; the standby resumes at the committed offset
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = active.Close()
+	}()
+
+	n, err = standby.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "two\n", string(buf[:n]))
+}
+
+func TestTailingReader_Checksum(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	store := NewFileCheckpointStore(file.Name() + ".checkpoint")
+	defer os.Remove(file.Name() + ".checkpoint")
+
+	tr, _ := NewTailingReader(file.Name(), WithChecksum(ChecksumSHA256), WithCheckpointStore(store))
+	buf := make([]byte, 7)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, ", string(buf[:n]))
+	assert.NoError(t, tr.SaveCheckpoint())
+	_ = tr.Close()
+
+	// the checksum continues after resuming from the checkpoint
+	tr, _ = NewTailingReader(file.Name(), WithChecksum(ChecksumSHA256), WithCheckpointStore(store))
+	defer tr.Close()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(buf[:n]))
+
+	sum := sha256.Sum256([]byte("Hello, World!"))
+	stats := tr.Stats()
+	assert.Equal(t, hex.EncodeToString(sum[:]), stats.Checksum)
+	assert.Equal(t, int64(13), stats.Offset)
+	assert.Equal(t, int64(6), stats.Delivered)
+
+	_, err = NewTailingReader(file.Name(), WithChecksum("md4"))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestTailingReader_ReadWithDetectModification(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithDetectModification(true))
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = file.WriteAt([]byte("J"), 0)
+	}()
+
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrModified)
+
+	// reading continues at the current offset
+	_, err = file.WriteString(" Bye!")
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " Bye!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithIgnoreChmod(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := NewTailingReader(file.Name(), WithIgnoreChmod(true), WithCloseOnTruncate(true), WithIdleTimeout(150*time.Millisecond))
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	_, err = tr.Read(buf)
+	assert.NoError(t, err)
+
+	// metadata changes don't wake the reader, so they don't extend the idle timeout
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			select {
+			case <-done:
+				return
+			case <-time.After(30 * time.Millisecond):
+				_ = os.Chmod(file.Name(), 0600|os.FileMode(i%2)<<5)
+			}
+		}
+	}()
+
+	start := time.Now()
+	_, err = tr.Read(buf)
+	close(done)
+	assert.Equal(t, ErrIdleTimeout, err)
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+
+	// truncation is still detected
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = file.Truncate(0)
+	}()
+
+	_, err = tr.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTailingReader_ReadWithEventMask(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithEventMask(fsnotify.Remove), WithIdleTimeout(200*time.Millisecond))
+	defer tr.Close()
+
+	// writes don't wake the reader
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = file.WriteString("Hello, World!")
+	}()
+
+	buf := make([]byte, 128)
+	_, err := tr.Read(buf)
+	assert.Equal(t, ErrIdleTimeout, err)
+
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+
+	tr, _ := NewTailingReader(path, WithWatchFile(true), WithWaitForFile(true, time.Second))
+	defer tr.Close()
+	assert.Equal(t, dir, tr.watched)
+
+	// the directory is watched until the file is created
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("Hello,"), 0644)
+	}()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello,", string(buf[:n]))
+
+	// then the file itself
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		_, _ = file.WriteString(" World!")
+		_ = file.Close()
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " World!", string(buf[:n]))
+	assert.Equal(t, path, tr.watched)
+}
+
+func TestTailingReader_ReadWithWatchBufferSize(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, err := NewTailingReader(file.Name(), WithWatchBufferSize(256*1024))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+
+	_, err = NewTailingReader(file.Name(), WithWatchBufferSize(-1))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestTailingReader_ReadWithFallbackPaths(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary.log")
+	secondary := filepath.Join(dir, "secondary.log")
+
+	err := os.WriteFile(secondary, []byte("secondary"), 0644)
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReader(primary, WithWaitForFile(true, 0), WithFallbackPaths(secondary))
+	assert.NoError(t, err)
+	defer tr.Close()
+	assert.Equal(t, secondary, tr.FilePath())
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "secondary", string(buf[:n]))
+
+	// the secondary disappears and the primary is created
+	assert.NoError(t, os.Remove(secondary))
+	assert.NoError(t, os.WriteFile(primary, []byte("primary"), 0644))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", string(buf[:n]))
+	assert.Equal(t, primary, tr.FilePath())
+
+	// the primary is preferred as long as it exists
+	assert.NoError(t, os.WriteFile(secondary, []byte("ignored"), 0644))
+	f, err := os.OpenFile(primary, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(" again")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " again", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithFallbackPathsBothExisting(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "a.log")
+	fallback := filepath.Join(dir, "b.log")
+	assert.NoError(t, os.WriteFile(primary, []byte("primary\n"), 0644))
+	assert.NoError(t, os.WriteFile(fallback, []byte("fallback\n"), 0644))
+
+	tr, err := NewTailingReader(primary, WithFallbackPaths(fallback))
+	assert.NoError(t, err)
+	defer tr.Close()
+	assert.Equal(t, primary, tr.FilePath())
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "primary\n", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithGlob(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewTailingReader(filepath.Join(dir, "app-[.log"), WithGlob(true))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = NewTailingReader(filepath.Join(dir, "*", "app.log"), WithGlob(true))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	tr, err := NewTailingReader(filepath.Join(dir, "app-*.log"), WithWaitForFile(true, 0), WithGlob(true))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(dir, "other.log"), []byte("other"), 0644)
+		_ = os.WriteFile(filepath.Join(dir, "app-20261015.log"), []byte("Hello, World!"), 0644)
+	}()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+	assert.Equal(t, filepath.Join(dir, "app-20261015.log"), tr.FilePath())
+}
+
+func TestTailingReader_ReadAfterTimeouts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+
+	tr, err := NewTailingReader(path, WithWaitForFile(true, 50*time.Millisecond), WithIdleTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrWaitTimeout)
+
+	assert.NoError(t, os.WriteFile(path, []byte("Hello"), 0644))
+
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+	assert.Equal(t, int64(5), tr.Offset())
+
+	// the file is replaced while nobody is reading
+	assert.NoError(t, os.Remove(path))
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrWaitTimeout)
+
+	assert.NoError(t, os.WriteFile(path, []byte("Hello, World!"), 0644))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithOnIdleTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	assert.NoError(t, os.WriteFile(path, []byte("Hello"), 0644))
+
+	var idles []time.Duration
+	tr, err := NewTailingReader(path, WithIdleTimeout(20*time.Millisecond), WithOnIdleTimeout(func(idle time.Duration) IdleAction {
+		idles = append(idles, idle)
+		switch len(idles) {
+		case 1, 2:
+			return IdleContinue
+		case 3:
+			return IdleReturnEOF
+		}
+		return IdleReturnError
+	}))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	// waiting continues until the callback decides otherwise
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Len(t, idles, 3)
+	assert.Less(t, idles[0], idles[1])
+	assert.Less(t, idles[1], idles[2])
+	assert.GreaterOrEqual(t, idles[2], 60*time.Millisecond)
+
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+}
+
+func TestTailingReader_ReadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	assert.NoError(t, os.Mkdir(path, 0755))
+
+	tr, err := NewTailingReader(path)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrNotAFile)
+
+	// with WaitForRegularFile, the reader waits until the directory is replaced by a file
+	tr2, err := NewTailingReader(path, WithWaitForFile(true, 0), WithWaitForRegularFile(true))
+	assert.NoError(t, err)
+	defer tr2.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.Remove(path)
+		_ = os.WriteFile(path, []byte("Hello"), 0644)
+	}()
+
+	n, err := tr2.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+}
+
+func TestTailingReader_ReadSymlinkTargetDeleted(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real", "app.log")
+	link := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.Mkdir(filepath.Dir(target), 0755))
+	assert.NoError(t, os.WriteFile(target, []byte("Hello"), 0644))
+	assert.NoError(t, os.Symlink(target, link))
+
+	tr, err := NewTailingReader(link, WithCloseOnDelete(true), WithIdleTimeout(5*time.Second))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.Remove(target)
+	}()
+
+	// the link remains, but its target is gone
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestTailingReader_ReadWithDetectUnlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("link counts are only supported on Unix")
+	}
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	assert.NoError(t, os.WriteFile(path, []byte("Hello"), 0644))
+
+	// Remove events are ignored, so only the link count reveals the deletion
+	tr, err := NewTailingReader(path, WithEventMask(fsnotify.Write), WithCloseOnDelete(true), WithDetectUnlink(true),
+		WithPollInterval(10*time.Millisecond), WithIdleTimeout(5*time.Second))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.Remove(path)
+	}()
+
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestTailingReader_ReadTruncatedAfterReplace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	assert.NoError(t, os.WriteFile(path, []byte("Hello"), 0644))
+
+	tr, err := NewTailingReader(path, WithCloseOnTruncate(true), WithIdleTimeout(2*time.Second))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	// the path is replaced by a larger file (Create isn't in the default mask), then the open file is truncated
+	old, err := os.OpenFile(path, os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	defer old.Close()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.log"), []byte("Hello, World!"), 0644))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "new.log"), path))
+	assert.NoError(t, old.Truncate(0))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		_, _ = file.WriteString("!")
+		_ = file.Close()
+	}()
+
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestRecordReader_ReadRecordAfterTimeout(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, err := NewTailingReader(file.Name(), WithIdleTimeout(50*time.Millisecond), WithTimeoutsAsEOF(true))
+	assert.NoError(t, err)
+	defer tr.Close()
+	rr := NewRecordReader(tr)
+
+	_, err = file.WriteString("first\n")
+	assert.NoError(t, err)
+
+	rec, err := rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(rec.Data))
+
+	_, err = rr.ReadRecord()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = file.WriteString("second\n")
+	assert.NoError(t, err)
+
+	rec, err = rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(rec.Data))
+	assert.Equal(t, int64(6), rec.Offset)
+}
+
+func TestRecordReader_ReadRecordWithLabels(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	labels := map[string]string{"service": "app", "env": "prod"}
+	tr, err := NewTailingReader(file.Name(), WithLabels(labels))
+	assert.NoError(t, err)
+	defer tr.Close()
+	rr := NewRecordReader(tr)
+
+	_, err = file.WriteString("first line\n")
+	assert.NoError(t, err)
+
+	rec, err := rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "first line", string(rec.Data))
+	assert.Equal(t, labels, rec.Labels)
+	assert.Zero(t, rec.Generation)
+
+	// reading starts over after truncation
+	assert.NoError(t, os.WriteFile(file.Name(), []byte("b\n"), 0644))
+
+	rec, err = rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(rec.Data))
+	assert.Equal(t, int64(0), rec.Offset)
+	assert.Equal(t, int64(1), rec.Generation)
+	assert.Equal(t, int64(1), tr.Stats().Generation)
+}
+
+func TestRecordReader_ReadRecordWithTimestampExtractor(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("2024-05-01T12:00:00Z first\nno timestamp\n[01/May/2024:14:30:00 +0200] third\n")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	tr, err := NewTailingReader(file.Name(), WithClock(&fakeClock{now: now}))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	extractor, err := NewLayoutExtractor(`^\S+`, time.RFC3339)
+	assert.NoError(t, err)
+	bracketed, err := NewLayoutExtractor(`^\[([^]]+)\]`, "02/Jan/2006:15:04:05 -0700")
+	assert.NoError(t, err)
+
+	// the first extractor finding a timestamp wins
+	rr := NewRecordReader(tr, WithTimestampExtractor(TimeParser(func(data []byte) (time.Time, bool) {
+		if ts, ok := extractor.ExtractTimestamp(data); ok {
+			return ts, true
+		}
+		return bracketed.ExtractTimestamp(data)
+	})))
+
+	rec, err := rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), rec.Timestamp)
+
+	// records without a timestamp fall back to the time they were read
+	rec, err = rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, now, rec.Timestamp)
+	assert.Equal(t, rec.Time, rec.EventTime())
+
+	rec, err = rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC).Equal(rec.Timestamp))
+	assert.Equal(t, rec.Timestamp, rec.EventTime())
+}
+
+func TestTailingReader_ReadAtOffset(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, err := NewTailingReader(file.Name(), WithReadahead(64))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	n, off, err := tr.ReadAtOffset(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+	assert.Equal(t, int64(0), off)
+
+	// served from the readahead buffer
+	n, off, err = tr.ReadAtOffset(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", Wor", string(buf[:n]))
+	assert.Equal(t, int64(5), off)
+
+	_, err = tr.Seek(7, io.SeekStart)
+	assert.NoError(t, err)
+
+	n, off, err = tr.ReadAtOffset(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World", string(buf[:n]))
+	assert.Equal(t, int64(7), off)
+}
+
+func TestTailingReader_CaughtUp(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReader(file.Name(), WithIdleTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 5)
+	for i := 0; i < 3; i++ {
+		_, err = tr.Read(buf)
+		assert.NoError(t, err)
+	}
+
+	select {
+	case <-tr.CaughtUp():
+		t.Fatal("caught up before reading all existing data")
+	default:
+	}
+
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+
+	select {
+	case <-tr.CaughtUp():
+	default:
+		t.Fatal("not caught up after reading all existing data")
+	}
+}
+
+func TestTailingReader_ReadWithCatchUp(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	clock := &fakeClock{}
+	tr, _ := NewTailingReader(file.Name(), WithCatchUp(5, 0), WithClock(clock))
+	defer tr.Close()
+
+	// existing data is throttled
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", Wor", string(buf[:n]))
+
+	go clock.Advance(time.Second)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ld!", string(buf[:n]))
+
+	// the reader only knows it has caught up once it waits for new data
+	go clock.Advance(time.Second)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = file.WriteString("0123456789")
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "01234", string(buf[:n]))
+
+	// live data isn't throttled, although the bucket has been used up
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "56789", string(buf[:n]))
+	assert.Equal(t, 2*time.Second, clock.Now().Sub(time.Time{}))
+}
+
+func TestRecordReader_ReadRecordWithStartAfterTime(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	base := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	var data bytes.Buffer
+	var offset int64
+	for i := 0; i < 10000; i++ {
+		if i == 6000 {
+			offset = int64(data.Len())
+		}
+		fmt.Fprintf(&data, "%s record %d\n", base.Add(time.Duration(i)*time.Second).Format(time.RFC3339), i)
+		if i%100 == 0 {
+			data.WriteString("\tcontinuation without timestamp\n")
+		}
+	}
+	_, err := file.Write(data.Bytes())
+	assert.NoError(t, err)
+
+	parse := func(data []byte) (time.Time, bool) {
+		ts, err := time.Parse(time.RFC3339, string(bytes.SplitN(data, []byte{' '}, 2)[0]))
+		return ts, err == nil
+	}
+
+	tr, err := NewTailingReader(file.Name())
+	assert.NoError(t, err)
+	defer tr.Close()
+	rr := NewRecordReader(tr, WithStartAfterTime(base.Add(5999*time.Second), parse))
+
+	rec, err := rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-15T01:40:00Z record 6000", string(rec.Data))
+	assert.Equal(t, offset, rec.Offset)
+
+	// everything after the first newer record is returned
+	rec, err = rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "\tcontinuation without timestamp", string(rec.Data))
+}
+
+func TestTailingReader_ReadWithStartAtPattern(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	// the pattern spans the boundary of the scan buffer
+	_, err := file.Write(bytes.Repeat([]byte{0}, 64*1024-2))
+	assert.NoError(t, err)
+	_, err = file.WriteString("MAGIC segment")
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReader(file.Name(), WithStartAtPattern([]byte("MAGIC")))
+	assert.NoError(t, err)
+	defer tr.Close()
+	assert.Equal(t, int64(64*1024-2), tr.Offset())
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "MAGIC segment", string(buf[:n]))
+
+	// without the pattern, only new data is read
+	tr2, err := NewTailingReader(file.Name(), WithStartAtPattern([]byte("OTHER")))
+	assert.NoError(t, err)
+	defer tr2.Close()
+
+	_, err = file.WriteString("!")
+	assert.NoError(t, err)
+
+	n, err = tr2.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithFollowDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = NewTailingReader(path, WithFollowMode("inode"))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	tr, err := NewTailingReader(path, WithFollowMode(FollowDescriptor), WithPollInterval(10*time.Millisecond))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = file.WriteString("Hello")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+
+	// the file is rotated away, but still written to
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, os.WriteFile(path, []byte("new file"), 0644))
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = file.WriteString(", World!")
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", World!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadAfterWatcherFailed(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, err := NewTailingReader(file.Name())
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = tr.watcher.Close()
+	}()
+
+	buf := make([]byte, 128)
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrWatcherFailed)
+}
+
+func TestTailingReader_ReadWithRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	w, err := tailtest.NewWriter(path)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// the path doesn't exist between the rotation and the next append
+	tr, err := NewTailingReader(path, WithWaitForFile(true, 0), WithIdleTimeout(500*time.Millisecond), WithTimeoutsAsEOF(true))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	done := w.Start(context.Background(),
+		tailtest.Append("first\n"),
+		tailtest.Rotate(".1").After(100*time.Millisecond),
+		tailtest.Append("second\n").After(100*time.Millisecond),
+		tailtest.CopyTruncate(".2").After(100*time.Millisecond),
+		tailtest.Append("third\n").After(100*time.Millisecond),
+	)
+
+	data, err := io.ReadAll(tr)
+	assert.NoError(t, err)
+	assert.NoError(t, <-done)
+	assert.Equal(t, "first\nsecond\nthird\n", string(data))
+}
+
+func TestTailingReader_ReadWithPrefetch(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, err := NewTailingReader(file.Name(), WithPrefetch(true), WithIdleTimeout(100*time.Millisecond))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(buf[:n]))
+	assert.Equal(t, int64(5), tr.Offset())
+
+	// data is read in the background meanwhile
+	assert.Eventually(t, func() bool {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		return tr.prefetched.Len() == 8
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(5), tr.Offset())
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ", Wor", string(buf[:n]))
+
+	// seeking discards the prefetched data
+	_, err = tr.Seek(7, io.SeekStart)
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World", string(buf[:n]))
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "!", string(buf[:n]))
+
+	// errors are returned once the data read before them has been returned
+	_, err = tr.Read(buf)
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+
+	_, err = file.WriteString(" Again!")
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " Agai", string(buf[:n]))
+}
+
+func TestRingBuffer(t *testing.T) {
+	b := newRingBuffer(8)
+
+	assert.Equal(t, 5, b.Write([]byte("Hello")))
+	buf := make([]byte, 3)
+	assert.Equal(t, 3, b.Read(buf))
+	assert.Equal(t, "Hel", string(buf))
+
+	// wraps around the end of the buffer
+	assert.Equal(t, 6, b.Write([]byte(", World!")))
+	assert.Equal(t, 0, b.Free())
+	assert.Equal(t, 8, b.Len())
+
+	buf = make([]byte, 16)
+	n := b.Read(buf)
+	assert.Equal(t, "lo, Worl", string(buf[:n]))
+	assert.Equal(t, 0, b.Len())
+
+	b.Write([]byte("Hi"))
+	b.Reset()
+	assert.Equal(t, 0, b.Read(buf))
+}
+
+func TestTailingReader_ReadWithBufferSize(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReader(file.Name(), WithPrefetch(true), WithBufferSize(4), WithReadahead(8))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hell", string(buf[:n]))
+
+	// reading in the background pauses once the buffer is full
+	assert.Eventually(t, func() bool {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		return tr.prefetched.Free() == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(4), tr.Offset())
+
+	var data []byte
+	for len(data) < 9 {
+		n, err = tr.Read(buf)
+		assert.NoError(t, err)
+		data = append(data, buf[:n]...)
+	}
+	assert.Equal(t, "o, World!", string(data))
+
+	_, err = NewTailingReader(file.Name(), WithBufferSize(-1))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestTailingReader_ReadWithSpill(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	spillDir := t.TempDir()
+
+	assert.NoError(t, os.WriteFile(path, []byte("Hello, World!"), 0644))
+
+	tr, err := NewTailingReader(path, WithPrefetch(true), WithBufferSize(4), WithSpill(spillDir, 7))
+	assert.NoError(t, err)
+
+	buf := make([]byte, 2)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "He", string(buf[:n]))
+
+	// data beyond the memory buffer is spilled to disk up to the limit
+	assert.Eventually(t, func() bool {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		return tr.spilled() == 7
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(2), tr.Offset())
+
+	// it's preserved although the file is deleted (but the rest of the file is lost)
+	assert.NoError(t, os.Remove(path))
+
+	var data []byte
+	for len(data) < 9 {
+		buf = make([]byte, 128)
+		n, err = tr.Read(buf)
+		assert.NoError(t, err)
+		data = append(data, buf[:n]...)
+	}
+	assert.Equal(t, "llo, Worl", string(data))
+
+	entries, _ := os.ReadDir(spillDir)
+	assert.Len(t, entries, 1)
+	assert.NoError(t, tr.Close())
+	entries, _ = os.ReadDir(spillDir)
+	assert.Len(t, entries, 0)
+}
+
+func TestTailingReader_ReadWithGrowthLimit(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	clock := &fakeClock{now: time.Now()}
+	step := func() {
+		clock.mu.Lock()
+		clock.now = clock.now.Add(time.Second)
+		clock.mu.Unlock()
+	}
+
+	var rates []int64
+	tr, _ := NewTailingReader(file.Name(), WithGrowthLimit(10, 2*time.Second), WithClock(clock))
+	defer tr.Close()
+	cb, _ := NewTailingReader(file.Name(), WithGrowthLimit(10, 2*time.Second), WithClock(clock), WithOnGrowthLimit(func(rate int64) {
+		rates = append(rates, rate)
+	}))
+	defer cb.Close()
+
+	buf := make([]byte, 1024)
+	read := func(tr *TailingReader) (int, error) {
+		return tr.Read(buf)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := file.WriteString(strings.Repeat("x", 100))
+		assert.NoError(t, err)
+
+		n, err := read(tr)
+		if i < 2 {
+			assert.NoError(t, err)
+			assert.Equal(t, 100, n)
+		} else {
+			// the file has grown by 100 bytes/s for 2 seconds
+			assert.ErrorIs(t, err, ErrGrowthLimit)
+			assert.Equal(t, 0, n)
+		}
+
+		n, err = read(cb)
+		assert.NoError(t, err)
+		assert.Equal(t, 100, n)
+
+		step()
+	}
+
+	// reading continues after the excess was reported (once)
+	_, err := file.WriteString(strings.Repeat("x", 100))
+	assert.NoError(t, err)
+	n, err := read(tr)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, n)
+
+	assert.Equal(t, []int64{100}, rates)
+}
+
+func TestTailingReader_ReadWithOnStall(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	stalls := make(chan bool, 2)
+	clock := &fakeClock{now: time.Now()}
+	tr, _ := NewTailingReader(file.Name(), WithClock(clock), WithOnStall(time.Minute, func(stalled bool) {
+		stalls <- stalled
+	}))
+	defer tr.Close()
+
+	result := make(chan string)
+	go func() {
+		buf := make([]byte, 128)
+		n, _ := tr.Read(buf)
+		result <- string(buf[:n])
+	}()
+
+	clock.Advance(time.Minute)
+	assert.True(t, <-stalls)
+
+	// the read continues waiting
+	select {
+	case data := <-result:
+		t.Fatalf("unexpected read of %q", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err := file.WriteString("Hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", <-result)
+	assert.False(t, <-stalls)
+}
+
+func TestTailingReader_ReadWithOnStallAndIdleTimeout(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	stalls := make(chan bool, 1)
+	tr, _ := NewTailingReader(file.Name(), WithIdleTimeout(300*time.Millisecond), WithOnStall(200*time.Millisecond, func(stalled bool) {
+		stalls <- stalled
+	}))
+	defer tr.Close()
+
+	// reporting the stall doesn't restart the idle timeout
+	start := time.Now()
+	buf := make([]byte, 128)
+	_, err := tr.Read(buf)
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+	assert.Less(t, time.Since(start), 450*time.Millisecond)
+	assert.True(t, <-stalls)
+}
+
+func TestTailingReader_ReadWithStabilityWindowAppendedAfterStat(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello\n")
+	assert.NoError(t, err)
+
+	var appendAfterStat atomic.Bool
+	stat := func(name string) (fs.FileInfo, error) {
+		info, err := os.Stat(name)
+		if appendAfterStat.CompareAndSwap(true, false) {
+			// a writer starts the next record right after the stat
+			_, _ = file.WriteString("par")
+		}
+		return info, err
+	}
+
+	clock := &fakeClock{}
+	tr, _ := NewTailingReader(file.Name(), WithStabilityWindow(time.Second), WithClock(clock), WithStatFunc(stat))
+	defer tr.Close()
+	appendAfterStat.Store(true)
+
+	// only the data up to the checked size is read
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello\n", string(buf[:n]))
+	assert.Equal(t, int64(6), tr.Offset())
+}
+
+func TestTailingReader_ReadWithStabilityWindow(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello\n")
+	assert.NoError(t, err)
+
+	clock := &fakeClock{}
+	tr, _ := NewTailingReader(file.Name(), WithStabilityWindow(time.Second), WithClock(clock))
+	defer tr.Close()
+
+	// newline-terminated data is read right away
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello\n", string(buf[:n]))
+
+	// a record written in two parts is delivered as a whole
+	_, err = file.WriteString("par")
+	assert.NoError(t, err)
+
+	result := make(chan string)
+	go func() {
+		n, _ := tr.Read(buf)
+		result <- string(buf[:n])
+	}()
+
+	select {
+	case data := <-result:
+		t.Fatalf("unexpected read of %q", data)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	_, err = file.WriteString("tial\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "partial\n", <-result)
+
+	// data without a newline is read once the size has been stable for the window
+	_, err = file.WriteString("torn")
+	assert.NoError(t, err)
+
+	go clock.Advance(time.Second)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "torn", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithRespectWriterLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("locks are mandatory on Windows and block reading anyway")
+	}
+
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, _ := NewTailingReader(file.Name(), WithRespectWriterLock(true), WithPollInterval(10*time.Millisecond))
+	defer tr.Close()
+
+	// the writer locks the file while writing a record
+	locked, err := tryLock(file)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	_, err = file.WriteString("Hello, ")
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = file.WriteString("World!")
+		_ = unlock(file)
+	}()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithRespectWriterLockAndNoWaitRead(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("locks are mandatory on Windows and block reading anyway")
+	}
+
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	var writeAfterStat atomic.Bool
+	stat := func(name string) (fs.FileInfo, error) {
+		info, err := os.Stat(name)
+		if writeAfterStat.CompareAndSwap(true, false) {
+			// the writer locks the file and starts writing a record right after the stat
+			_, _ = tryLock(file)
+			_, _ = file.WriteString("Hello, ")
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				_, _ = file.WriteString("World!")
+				_ = unlock(file)
+			}()
+		}
+		return info, err
+	}
+
+	tr, _ := NewTailingReader(file.Name(), WithRespectWriterLock(true), WithNoWaitRead(true), WithStatFunc(stat), WithPollInterval(10*time.Millisecond))
+	defer tr.Close()
+
+	_, err := file.WriteString("first\n")
+	assert.NoError(t, err)
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "first\n", string(buf[:n]))
+
+	// reading without waiting respects the lock as well
+	writeAfterStat.Store(true)
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_ReadWithLongPath(t *testing.T) {
+	dir := t.TempDir()
+
+	// exceeds MAX_PATH on Windows
+	path := filepath.Join(dir, strings.Repeat("nested-directory-", 10), strings.Repeat("deeper-directory-", 10), "test.log")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte("Hello, "), 0644))
+
+	tr, err := NewTailingReader(path)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, ", string(buf[:n]))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		_, _ = file.WriteString("World!")
+		_ = file.Close()
+	}()
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "World!", string(buf[:n]))
+}
+
+// staleFileInfo reports an outdated size, like NFS clients caching attributes do
+type staleFileInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (info staleFileInfo) Size() int64 {
+	return info.size
+}
+
+func TestTailingReader_ReadWithNFS(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	stale := false
+	stat := func(name string) (fs.FileInfo, error) {
+		info, err := os.Stat(name)
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil && stale {
+			return staleFileInfo{info, 5}, nil
+		}
+		return info, err
+	}
+	setStale := func(s bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		stale = s
+	}
+
+	clock := &fakeClock{}
+	tr, _ := NewTailingReader(file.Name(), WithNFS(true), WithPollInterval(10*time.Millisecond), WithStatFunc(stat), WithClock(clock))
+	defer tr.Close()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+
+	result := make(chan string)
+	read := func() {
+		n, _ := tr.Read(buf)
+		result <- string(buf[:n])
+	}
+
+	// an outdated size isn't mistaken for a truncation
+	setStale(true)
+	_, err = file.WriteString("!!")
+	assert.NoError(t, err)
+
+	go read()
+	clock.Advance(10 * time.Millisecond)
+
+	select {
+	case data := <-result:
+		t.Fatalf("unexpected read of %q", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	setStale(false)
+	clock.Advance(10 * time.Millisecond)
+	assert.Equal(t, "!!", <-result)
+
+	// a file that stays smaller is truncated
+	assert.NoError(t, os.WriteFile(file.Name(), []byte("new"), 0644))
+
+	go read()
+	clock.Advance(10 * time.Millisecond)
+
+	select {
+	case data := <-result:
+		t.Fatalf("unexpected read of %q", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, "new", <-result)
+}
+
+func TestTailingReader_ReadWithDecomposedName(t *testing.T) {
+	if runtime.GOOS != "darwin" {
+		t.Skip("only macOS file systems ignore Unicode normalization")
+	}
+
+	dir := t.TempDir()
+
+	// the file is created with a decomposed name (NFD) but tailed by its composed name (NFC)
+	decomposed := filepath.Join(dir, "cafe\u0301.log")
+	composed := filepath.Join(dir, "caf\u00e9.log")
+
+	tr, err := NewTailingReader(composed)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(decomposed, []byte("Hello, World!"), 0644)
+	}()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_EventPathCaseInsensitive(t *testing.T) {
+	dir := t.TempDir()
+
+	tr, err := NewTailingReader(filepath.Join(dir, "Test.log"), WithCaseInsensitive(true), WithFallbackPaths(filepath.Join(dir, "Other.log")))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	assert.Equal(t, tr.eventPath(filepath.Join(dir, "Test.log")), tr.eventPath(filepath.Join(dir, "TEST.LOG")))
+	assert.True(t, tr.isCandidate(filepath.Join(dir, "other.log")))
+	assert.False(t, tr.isCandidate(filepath.Join(dir, "another.log")))
+}
+
+func TestTailingReader_ReadWithUncleanPath(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+
+	// event names are based on the cleaned directory
+	tr, err := NewTailingReader(dir + "/sub/../test.log")
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(dir, "test.log"), []byte("Hello, World!"), 0644)
+	}()
+
+	buf := make([]byte, 128)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+}
+
+func TestTailingReader_EventPathResolveSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "real"), 0755))
+	assert.NoError(t, os.Symlink("real", filepath.Join(dir, "link")))
+
+	tr, err := NewTailingReader(filepath.Join(dir, "real", "a.log"), WithResolveSymlinks(true), WithFallbackPaths(filepath.Join(dir, "link", "b.log")))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	assert.True(t, tr.isCandidate(filepath.Join(dir, "real", "b.log")))
+	assert.True(t, tr.isCandidate(filepath.Join(dir, "link", "a.log")))
+}
+
+func TestTailingReader_Run(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	// the handler's error ends Run
+	tr, _ := NewTailingReader(file.Name())
+	errStop := errors.New("stop")
+	var data []byte
+	err = tr.Run(context.Background(), func(chunk Chunk) error {
+		data = append(data, chunk.Data...)
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, "Hello, World!", string(data))
+
+	// the reader is closed on return
+	_, err = tr.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrClosed)
+
+	// cancelling ctx ends Run
+	tr, _ = NewTailingReader(file.Name(), WithStartAtEnd(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = tr.Run(ctx, func(chunk Chunk) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// reading to io.EOF ends Run without an error
+	tr, _ = NewTailingReader(file.Name(), WithIdleTimeout(50*time.Millisecond), WithTimeoutsAsEOF(true))
+	data = nil
+	err = tr.Run(context.Background(), func(chunk Chunk) error {
+		data = append(data, chunk.Data...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(data))
+}
+
+func TestTailingReader_Shutdown(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	store := NewFileCheckpointStore(file.Name() + ".checkpoint")
+	defer os.Remove(file.Name() + ".checkpoint")
+
+	tr, _ := NewTailingReader(file.Name(), WithCheckpointStore(store))
+
+	var data []byte
+	done := make(chan error)
+	go func() {
+		buf := make([]byte, 4)
+		for {
+			n, err := tr.Read(buf)
+			data = append(data, buf[:n]...)
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+
+	_, err := file.WriteString("Hello, ")
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	// data written right before the shutdown is still read
+	_, err = file.WriteString("World!")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, tr.Shutdown(ctx))
+
+	assert.Equal(t, io.EOF, <-done)
+	assert.Equal(t, "Hello, World!", string(data))
+
+	cp, err := store.Load(file.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13), cp.Offset)
+
+	_, err = tr.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, tr.Shutdown(ctx), ErrClosed)
+
+	// without a consumer, the reader is closed once ctx is done
+	tr, _ = NewTailingReader(file.Name())
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, tr.Shutdown(ctx), context.DeadlineExceeded)
+
+	_, err = tr.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestParseManagerConfig(t *testing.T) {
+	cfg, err := ParseManagerConfig([]byte(`{"options": {"idle_timeout": "1m"}, "labels": {"env": "prod", "service": "web"}, "files": [{"path": "/var/log/a.log", "options": {"start_at_end": "true"}, "labels": {"service": "app"}}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"idle_timeout": "1m"}, cfg.Options)
+	assert.Equal(t, []ManagerFile{{Path: "/var/log/a.log", Options: map[string]string{"start_at_end": "true"}, Labels: map[string]string{"service": "app"}}}, cfg.Files)
+
+	files, err := cfg.FileOptions()
+	assert.NoError(t, err)
+
+	var opts Options
+	for _, option := range files["/var/log/a.log"] {
+		option(&opts)
+	}
+	assert.Equal(t, time.Minute, opts.IdleTimeout)
+	assert.True(t, opts.StartAtEnd)
+	assert.Equal(t, map[string]string{"env": "prod", "service": "app"}, opts.Labels)
+
+	cfg.Files[0].Options["start_at_ned"] = "true"
+	_, err = cfg.FileOptions()
+	assert.ErrorContains(t, err, "start_at_ned")
+}
+
+func TestManager_Apply(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.log")
+	b := filepath.Join(dir, "b.log")
+	c := filepath.Join(dir, "c.txt")
+	for _, path := range []string{a, b, c} {
+		assert.NoError(t, os.WriteFile(path, []byte("Hello\n"), 0644))
+	}
+
+	cfg, err := ParseManagerConfig([]byte(`
+options:
+  idle_timeout: 100ms
+files:
+  - path: ` + filepath.Join(dir, "*.log") + `
+  - path: ` + c + `
+    options:
+      start_at_end: true
+`))
+	assert.NoError(t, err)
+
+	m, err := NewManagerFromConfig(cfg, nil, WithTimeoutsAsEOF(true))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	readers := m.Readers()
+	assert.Len(t, readers, 3)
+
+	buf := make([]byte, 128)
+	n, err := readers[a].Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello\n", string(buf[:n]))
+
+	// start_at_end only applies to c, the common options to all files
+	_, err = readers[c].Read(buf)
+	assert.Equal(t, io.EOF, err)
+
+	// files removed from the config are no longer tailed
+	cfg.Files = cfg.Files[1:]
+	assert.NoError(t, m.Apply(cfg))
+	assert.Len(t, m.Readers(), 1)
+	_, err = readers[a].Read(buf)
+	assert.ErrorIs(t, err, ErrClosed)
+
+	_, ok := m.Reader(c)
+	assert.True(t, ok)
+	_, err = m.Add(c)
+	assert.Error(t, err)
+
+	assert.NoError(t, m.Remove(c))
+	assert.Empty(t, m.Readers())
+
+	assert.NoError(t, m.Close())
+	_, err = m.Add(a)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestManager_Run(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.log")
+	b := filepath.Join(dir, "b.log")
+	assert.NoError(t, os.WriteFile(a, []byte("a1\na2\n"), 0644))
+	assert.NoError(t, os.WriteFile(b, []byte("b1\n"), 0644))
+
+	all, some := &memorySink{}, &memorySink{}
+	m, err := NewManagerFromConfig(ManagerConfig{Files: []ManagerFile{
+		{Path: a},
+		{Path: b, Sinks: []string{"some"}},
+	}}, map[string]Sink{"all": all, "some": some})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(all.lines()) == 2 && len(some.lines()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	// files added while running are included
+	c := filepath.Join(dir, "c.log")
+	assert.NoError(t, os.WriteFile(c, []byte("c1\n"), 0644))
+	_, err = m.Add(c)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(all.lines()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"a1", "a2", "c1"}, all.lines())
+	assert.ElementsMatch(t, []string{"a1", "a2", "b1", "c1"}, some.lines())
+	assert.Positive(t, all.flushCount())
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+	assert.Empty(t, m.Readers())
+}
+
+func TestManager_RunWithIdleTimeout(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.log")
+	b := filepath.Join(dir, "b.log")
+	assert.NoError(t, os.WriteFile(a, []byte("a1\n"), 0644))
+	assert.NoError(t, os.WriteFile(b, []byte("b1\n"), 0644))
+
+	sink := &memorySink{}
+	m := NewManager()
+	assert.NoError(t, m.AddSink("sink", sink))
+	_, err := m.Add(a, WithIdleTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	_, err = m.Add(b)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(sink.lines()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the idle timeouts of a neither end the run nor reading a
+	time.Sleep(200 * time.Millisecond)
+	for path, data := range map[string]string{a: "a2\n", b: "b2\n"} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		assert.NoError(t, err)
+		_, err = f.WriteString(data)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(sink.lines()) == 4
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"a1", "a2", "b1", "b2"}, sink.lines())
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+}
+
+func TestManager_RunWithFailingSink(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello\n")
+	assert.NoError(t, err)
+
+	failure := errors.New("unavailable")
+	m := NewManager()
+	assert.NoError(t, m.AddSink("failing", &memorySink{err: failure}))
+	_, err = m.Add(file.Name())
+	assert.NoError(t, err)
+
+	err = m.Run(context.Background())
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorContains(t, err, "sink failing")
+}
+
+func TestManager_RunWithSinkRetries(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello\nWorld\n")
+	assert.NoError(t, err)
+
+	sink := &memorySink{}
+	m := NewManager()
+	assert.NoError(t, m.AddSink("flaky", &flakySink{Sink: sink, failures: 3}, WithSinkRetries(3, time.Millisecond, time.Millisecond)))
+	_, err = m.Add(file.Name())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result := make(chan error)
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(sink.lines()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"Hello", "World"}, sink.lines())
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+
+	// retries are exhausted
+	m = NewManager()
+	assert.NoError(t, m.AddSink("flaky", &flakySink{Sink: sink, failures: 3}, WithSinkRetries(2, time.Millisecond, time.Millisecond)))
+	_, err = m.Add(file.Name())
+	assert.NoError(t, err)
+
+	err = m.Run(context.Background())
+	assert.ErrorContains(t, err, "sink flaky")
+}
+
+func TestManager_RunWithDeliveryMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.log")
+	assert.NoError(t, os.WriteFile(path, []byte("a1\na2\n"), 0644))
+
+	for _, mode := range []DeliveryMode{AtLeastOnce, AtMostOnce} {
+		store := NewFileCheckpointStore(filepath.Join(dir, fmt.Sprintf("checkpoints-%d.json", mode)))
+
+		m := NewManager(WithCheckpointStore(store))
+		assert.NoError(t, m.AddSink("failing", &memorySink{err: errors.New("unavailable")}, WithDeliveryMode(mode)))
+		_, err := m.Add(path)
+		assert.NoError(t, err)
+
+		err = m.Run(context.Background())
+		assert.ErrorContains(t, err, "sink failing")
+
+		cp, err := store.Load(path)
+		if mode == AtLeastOnce {
+			// the records were not acknowledged
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, int64(6), cp.Offset)
+		}
+	}
+}
+
+func TestManager_UseRecordOptions(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("2024-05-01T12:00:00Z Hello\n")
+	assert.NoError(t, err)
+
+	extractor, err := NewLayoutExtractor(`^\S+`, time.RFC3339)
+	assert.NoError(t, err)
+
+	sink := &memorySink{}
+	m := NewManager()
+	assert.NoError(t, m.UseRecordOptions(WithTimestampExtractor(extractor)))
+	assert.NoError(t, m.AddSink("sink", sink))
+	_, err = m.Add(file.Name())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(sink.lines()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, m.UseRecordOptions(), errManagerRunning)
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), sink.records[0].Timestamp)
+}
+
+func TestManager_RunWithDecoders(t *testing.T) {
+	dir := t.TempDir()
+	lines := filepath.Join(dir, "app.log")
+	docker := filepath.Join(dir, "abc-json.log")
+	jsonl := filepath.Join(dir, "events.jsonl")
+	binary := filepath.Join(dir, "data.bin")
+
+	assert.NoError(t, os.WriteFile(lines, []byte("plain\n"), 0644))
+	assert.NoError(t, os.WriteFile(docker, []byte(`{"log":"from docker\n","stream":"stderr","time":"2024-05-01T12:00:00.5Z"}`+"\n"), 0644))
+	assert.NoError(t, os.WriteFile(jsonl, []byte(`{"time":"2024-05-01T12:00:00Z","level":"warn","msg":"event"}`+"\n\n"), 0644))
+	assert.NoError(t, os.WriteFile(binary, []byte("\x00\x00\x00\x03a\nb\x00\x00\x00\x00"), 0644))
+
+	cfg, err := ParseManagerConfig([]byte(`
+files:
+  - path: ` + filepath.Join(dir, "*") + `
+decoders:
+  - pattern: "*-json.log"
+    decoder: docker
+  - pattern: "*.jsonl"
+    decoder: jsonl
+`))
+	assert.NoError(t, err)
+
+	sink := &memorySink{}
+	m, err := NewManagerFromConfig(cfg, map[string]Sink{"sink": sink})
+	assert.NoError(t, err)
+	assert.NoError(t, m.AddDecoder(filepath.Join(dir, "*.bin"), &LengthPrefixedDecoder{}))
+	assert.Error(t, m.AddDecoder("[", LineDecoder{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(sink.lines()) == 5
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+
+	records := make(map[string][]Record)
+	for _, rec := range sink.records {
+		records[filepath.Base(rec.Path)] = append(records[filepath.Base(rec.Path)], rec)
+	}
+
+	assert.Equal(t, "plain", string(records["app.log"][0].Data))
+
+	assert.Equal(t, "from docker", string(records["abc-json.log"][0].Data))
+	assert.Equal(t, map[string]string{"stream": "stderr"}, records["abc-json.log"][0].Labels)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 5e8, time.UTC), records["abc-json.log"][0].Timestamp)
+
+	// blank lines are dropped
+	assert.Len(t, records["events.jsonl"], 1)
+	assert.Equal(t, LevelWarn, records["events.jsonl"][0].Level)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), records["events.jsonl"][0].Timestamp)
+
+	assert.Len(t, records["data.bin"], 2)
+	assert.Equal(t, "a\nb", string(records["data.bin"][0].Data))
+	assert.Empty(t, records["data.bin"][1].Data)
+	assert.Equal(t, int64(7), records["data.bin"][1].Offset)
+
+	_, err = NewDecoder("xml")
+	assert.ErrorIs(t, err, ErrUnknownDecoder)
+}
+
+func TestRecordReader_ReadRecordWithSplitFunc(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	tr, err := NewTailingReader(file.Name(), WithIdleTimeout(50*time.Millisecond), WithTimeoutsAsEOF(true))
+	assert.NoError(t, err)
+	defer tr.Close()
+	rr := NewRecordReader(tr, (&LengthPrefixedDecoder{MaxSize: 16}).RecordOptions()...)
+
+	// incomplete records are kept at EOF
+	_, err = file.WriteString("\x00\x00\x00\x05hel")
+	assert.NoError(t, err)
+
+	_, err = rr.ReadRecord()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = file.WriteString("lo\x00\x00\x01\x00")
+	assert.NoError(t, err)
+
+	rec, err := rr.ReadRecord()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(rec.Data))
+	assert.Equal(t, int64(0), rec.Offset)
+
+	_, err = rr.ReadRecord()
+	assert.ErrorContains(t, err, "256 bytes")
+}
+
+func TestManager_RunWithWAL(t *testing.T) {
+	dir := t.TempDir()
+	walDir := filepath.Join(dir, "wal")
+	path := filepath.Join(dir, "a.log")
+	assert.NoError(t, os.WriteFile(path, []byte("a1\na2\n"), 0644))
+
+	// tailing continues while the sink fails
+	sink := &memorySink{err: errors.New("unavailable")}
+	m := NewManager()
+	assert.NoError(t, m.UseWAL(walDir, 0))
+	assert.NoError(t, m.AddSink("sink", sink))
+	tr, err := m.Add(path)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return tr.Offset() == 6
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, sink.lines())
+
+	// undelivered records are delivered by the next run
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+
+	sink = &memorySink{err: errors.New("unavailable")}
+	m = NewManager()
+	assert.NoError(t, m.UseWAL(walDir, 0))
+	assert.NoError(t, m.AddSink("sink", sink))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		result <- m.Run(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	sink.setErr(nil)
+
+	assert.Eventually(t, func() bool {
+		return len(sink.lines()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"a1", "a2"}, sink.lines())
+	assert.Equal(t, path, sink.records[0].Path)
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+
+	info, err := os.Stat(filepath.Join(walDir, "sink", "wal"))
+	assert.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestWAL(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openWAL(dir, 0)
+	assert.NoError(t, err)
+
+	now := time.Unix(0, time.Now().UnixNano())
+	records := []Record{
+		{Path: "/var/log/a.log", Offset: 0, Time: now, Data: []byte("first"), Generation: 2, Labels: map[string]string{"service": "app"}},
+		{Path: "/var/log/a.log", Offset: 6, Time: now, Timestamp: now.Add(-time.Hour), Level: LevelWarn, Data: []byte("second")},
+		{Path: "/var/log/b.log", Offset: 0, Time: now, Data: []byte("third")},
+	}
+	assert.NoError(t, w.append(context.Background(), records))
+
+	read, offset, err := w.read(2)
+	assert.NoError(t, err)
+	assert.Equal(t, records[:2], read)
+	assert.NoError(t, w.commit(offset))
+
+	// a torn entry at the end is dropped
+	_, err = w.file.WriteAt([]byte{0, 0, 0, 42, 1, 2}, w.size)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	w, err = openWAL(dir, 0)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	read, offset, err = w.read(10)
+	assert.NoError(t, err)
+	assert.Equal(t, records[2:], read)
+	assert.NoError(t, w.commit(offset))
+	assert.Zero(t, w.size)
+
+	// appending waits while the log is full
+	w.maxSize = int64(len(appendWALEntry(nil, records[0])))
+	assert.NoError(t, w.append(context.Background(), records[:1]))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, w.append(ctx, records[1:2]), context.DeadlineExceeded)
+	assert.ErrorIs(t, w.append(context.Background(), records), errWALFull)
+
+	// a corrupt entry before the end is an error, after the records before it
+	w.maxSize = 0
+	assert.NoError(t, w.append(context.Background(), records[1:]))
+	_, err = w.file.WriteAt([]byte("X"), int64(len(appendWALEntry(nil, records[0])))+walHeaderSize+1)
+	assert.NoError(t, err)
+
+	read, offset, err = w.read(10)
+	assert.NoError(t, err)
+	assert.Equal(t, records[:1], read)
+	assert.NoError(t, w.commit(offset))
+
+	_, _, err = w.read(10)
+	assert.ErrorIs(t, err, errWALCorrupt)
+
+	// a corrupt length before the end is an error too, and nothing is dropped
+	assert.NoError(t, w.commit(w.size))
+	assert.NoError(t, w.append(context.Background(), records))
+	size := w.size
+	_, err = w.file.WriteAt([]byte{0xff}, 0)
+	assert.NoError(t, err)
+
+	_, _, err = w.read(10)
+	assert.ErrorIs(t, err, errWALCorrupt)
+	assert.ErrorIs(t, err, errWALHeader)
+	assert.Equal(t, size, w.size)
+
+	// an entry reaching beyond the end was torn while appending and is dropped
+	assert.NoError(t, w.commit(w.size))
+	assert.NoError(t, w.append(context.Background(), records[:1]))
+	entry := appendWALEntry(nil, records[1])
+	_, err = w.file.WriteAt(entry[:len(entry)-1], w.size)
+	assert.NoError(t, err)
+	w.size += int64(len(entry) - 1)
+
+	read, offset, err = w.read(10)
+	assert.NoError(t, err)
+	assert.Equal(t, records[:1], read)
+	assert.Equal(t, offset, w.size)
+}
+
+func TestManager_RunWithCorruptWAL(t *testing.T) {
+	walDir := t.TempDir()
+	w, err := openWAL(filepath.Join(walDir, "sink"), 0)
+	assert.NoError(t, err)
+	records := []Record{{Path: "a.log", Data: []byte("first")}, {Path: "a.log", Data: []byte("second")}}
+	assert.NoError(t, w.append(context.Background(), records))
+	_, err = w.file.WriteAt([]byte("X"), walHeaderSize+1)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	// the run fails instead of silently no longer delivering to the sink
+	sink := &memorySink{}
+	m := NewManager()
+	assert.NoError(t, m.UseWAL(walDir, 0))
+	assert.NoError(t, m.AddSink("sink", sink))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = m.Run(ctx)
+	assert.ErrorIs(t, err, errWALCorrupt)
+	assert.Empty(t, sink.lines())
+}
+
+// flakySink is a Sink failing the first writes
+type flakySink struct {
+	Sink
+	failures int
+}
+
+func (s *flakySink) Write(ctx context.Context, rec Record) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	return s.Sink.Write(ctx, rec)
+}
+
+// memorySink is a Sink keeping the records written to it
+type memorySink struct {
+	mu      sync.Mutex
+	records []Record
+	flushes int
+	err     error // returned by Write
+}
+
+func (s *memorySink) Write(ctx context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *memorySink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flushes++
+	return nil
+}
+
+func (s *memorySink) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+func (s *memorySink) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines []string
+	for _, rec := range s.records {
+		lines = append(lines, string(rec.Data))
+	}
+	return lines
+}
+
+func (s *memorySink) flushCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flushes
+}
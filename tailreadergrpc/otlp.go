@@ -0,0 +1,157 @@
+package tailreadergrpc
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/maurice2k/tailreader/tailreadergrpc/otlppb"
+)
+
+// DefaultOTLPBatchSize is the maximum number of records exported at once if none is set
+const DefaultOTLPBatchSize = 1000
+
+// otlpExportMethod is the full name of the OTLP logs export method
+const otlpExportMethod = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// OTLPSink is a tailreader.Sink exporting records as OpenTelemetry LogRecords over OTLP/gRPC
+//
+// Records are grouped into one resource per file with the attributes
+// host.name, log.file.path and log.file.name in addition to Attributes; the
+// labels of records (see tailreader.WithLabels) are their attributes, and their
+// levels (see tailreader.WithLevelExtractor) their severities. They
+// are exported in batches of up to BatchSize, and whatever is left when the
+// Manager flushes the sink. Records the collector rejects (as partial success)
+// are not exported again.
+type OTLPSink struct {
+	// Conn is the connection to the collector, e.g. from grpc.NewClient
+	Conn grpc.ClientConnInterface
+
+	// Attributes are additional resource attributes, e.g. service.name
+	Attributes map[string]string
+
+	Host string // defaults to os.Hostname()
+
+	// BatchSize is the maximum number of records exported at once; 0 uses DefaultOTLPBatchSize
+	BatchSize int
+
+	resources map[string]*otlppb.ResourceLogs // resources of the current batch by path
+	size      int                             // number of records in the current batch
+}
+
+func (s *OTLPSink) Write(ctx context.Context, rec tailreader.Record) error {
+	if s.resources == nil {
+		s.resources = make(map[string]*otlppb.ResourceLogs)
+	}
+
+	resource, ok := s.resources[rec.Path]
+	if !ok {
+		resource = &otlppb.ResourceLogs{
+			Resource:  &otlppb.Resource{Attributes: s.resourceAttributes(rec.Path)},
+			ScopeLogs: []*otlppb.ScopeLogs{{Scope: &otlppb.InstrumentationScope{Name: "github.com/maurice2k/tailreader"}}},
+		}
+		s.resources[rec.Path] = resource
+	}
+
+	scope := resource.ScopeLogs[0]
+	scope.LogRecords = append(scope.LogRecords, &otlppb.LogRecord{
+		TimeUnixNano:         uint64(rec.EventTime().UnixNano()),
+		ObservedTimeUnixNano: uint64(rec.Time.UnixNano()),
+		SeverityNumber:       severityNumber(rec.Level),
+		SeverityText:         rec.Level.String(),
+		Body:                 bodyValue(rec.Data),
+		Attributes:           keyValues(rec.Labels),
+	})
+	s.size++
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultOTLPBatchSize
+	}
+	if s.size >= batchSize {
+		err := s.Flush(ctx)
+		if err != nil {
+			// drop the log record, and the resource if rec added it; it's exported with the retry
+			scope.LogRecords = scope.LogRecords[:len(scope.LogRecords)-1]
+			if !ok {
+				delete(s.resources, rec.Path)
+			}
+			s.size--
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *OTLPSink) Flush(ctx context.Context) error {
+	if s.size == 0 {
+		return nil
+	}
+
+	req := &otlppb.ExportLogsServiceRequest{}
+	for _, path := range slices.Sorted(maps.Keys(s.resources)) {
+		req.ResourceLogs = append(req.ResourceLogs, s.resources[path])
+	}
+
+	err := s.Conn.Invoke(ctx, otlpExportMethod, req, &otlppb.ExportLogsServiceResponse{})
+	if err != nil {
+		// keep the batch to export it again with the next flush
+		return err
+	}
+
+	s.resources = nil
+	s.size = 0
+	return nil
+}
+
+// resourceAttributes returns the attributes of the resource of the file at path
+func (s *OTLPSink) resourceAttributes(path string) []*otlppb.KeyValue {
+	host := s.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	attributes := map[string]string{
+		"host.name":     host,
+		"log.file.path": path,
+		"log.file.name": filepath.Base(path),
+	}
+	maps.Copy(attributes, s.Attributes)
+	return keyValues(attributes)
+}
+
+// keyValues returns the attributes sorted by key
+func keyValues(attributes map[string]string) []*otlppb.KeyValue {
+	var kvs []*otlppb.KeyValue
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		kvs = append(kvs, &otlppb.KeyValue{Key: key, Value: stringValue(attributes[key])})
+	}
+	return kvs
+}
+
+// severityNumber returns the OTLP severity number of level, 0 (unspecified) for LevelNone
+func severityNumber(level tailreader.Level) int32 {
+	if level == tailreader.LevelNone {
+		return 0
+	}
+	// TRACE is 1, DEBUG 5, INFO 9 and so on
+	return int32(level-tailreader.LevelTrace)*4 + 1
+}
+
+// bodyValue returns data as string value, or as bytes value if it's not valid UTF-8
+func bodyValue(data []byte) *otlppb.AnyValue {
+	if !utf8.Valid(data) {
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_BytesValue{BytesValue: data}}
+	}
+	return stringValue(string(data))
+}
+
+func stringValue(s string) *otlppb.AnyValue {
+	return &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: s}}
+}
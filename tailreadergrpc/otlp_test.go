@@ -0,0 +1,105 @@
+package tailreadergrpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/maurice2k/tailreader/tailreadergrpc/otlppb"
+)
+
+// fakeCollector receives the requests of the OTLP logs service
+type fakeCollector struct {
+	mu       sync.Mutex
+	requests []*otlppb.ExportLogsServiceRequest
+}
+
+func (c *fakeCollector) export(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+	req := &otlppb.ExportLogsServiceRequest{}
+	err := dec(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+	return &otlppb.ExportLogsServiceResponse{}, nil
+}
+
+func newTestCollector(t *testing.T) (*fakeCollector, *grpc.ClientConn) {
+	collector := &fakeCollector{}
+
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "opentelemetry.proto.collector.logs.v1.LogsService",
+		HandlerType: (*any)(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "Export", Handler: collector.export}},
+	}, collector)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return collector, conn
+}
+
+func TestOTLPSink(t *testing.T) {
+	collector, conn := newTestCollector(t)
+
+	s := &OTLPSink{Conn: conn, Host: "web1", Attributes: map[string]string{"service.name": "app"}}
+
+	ts := time.Unix(1700000000, 5)
+	for _, rec := range []tailreader.Record{
+		{Path: "/var/log/app.log", Data: []byte("Hello"), Time: ts},
+		{Path: "/var/log/app.log", Data: []byte{0xff}, Time: ts},
+		{Path: "/var/log/other.log", Data: []byte("World"), Time: ts},
+	} {
+		assert.NoError(t, s.Write(context.Background(), rec))
+	}
+	assert.Empty(t, collector.requests)
+
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, collector.requests, 1)
+
+	resources := collector.requests[0].ResourceLogs
+	assert.Len(t, resources, 2)
+
+	attributes := make(map[string]string)
+	for _, kv := range resources[0].Resource.Attributes {
+		attributes[kv.Key] = kv.Value.GetStringValue()
+	}
+	assert.Equal(t, map[string]string{
+		"host.name":     "web1",
+		"log.file.path": "/var/log/app.log",
+		"log.file.name": "app.log",
+		"service.name":  "app",
+	}, attributes)
+
+	records := resources[0].ScopeLogs[0].LogRecords
+	assert.Len(t, records, 2)
+	assert.Equal(t, "Hello", records[0].Body.GetStringValue())
+	assert.Equal(t, []byte{0xff}, records[1].Body.GetBytesValue())
+	assert.Equal(t, uint64(ts.UnixNano()), records[0].ObservedTimeUnixNano)
+	assert.Equal(t, "World", resources[1].ScopeLogs[0].LogRecords[0].Body.GetStringValue())
+
+	// nothing left to export
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, collector.requests, 1)
+}
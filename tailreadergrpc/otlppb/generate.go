@@ -0,0 +1,4 @@
+// Package otlppb contains the protobuf messages of the OTLP logs protocol used by OTLPSink
+package otlppb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative otlp.proto
@@ -0,0 +1,159 @@
+// Package tailreadergrpc exposes tailed files through a gRPC streaming service.
+package tailreadergrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/maurice2k/tailreader/tailreadergrpc/tailreaderpb"
+)
+
+// DefaultChunkSize is the maximum size of a streamed chunk if none is set
+const DefaultChunkSize = 32 * 1024
+
+// Server implements tailreaderpb.TailServiceServer
+//
+// Requested paths are resolved relative to Root; absolute paths and paths
+// escaping Root are rejected. Every call gets its own reader, which is closed
+// as soon as the client cancels. Send blocks while the client's flow control
+// window is exhausted, so a slow subscriber simply stops the reader instead of
+// having data buffered on its behalf.
+type Server struct {
+	tailreaderpb.UnimplementedTailServiceServer
+
+	// Root is the directory requested paths are resolved against
+	Root string
+
+	// Options are applied to every reader before the options of the request
+	Options []tailreader.Option
+
+	// ChunkSize is the maximum size of a chunk; 0 uses DefaultChunkSize
+	ChunkSize int
+}
+
+// NewServer returns a Server serving files below root with the given options
+func NewServer(root string, options ...tailreader.Option) *Server {
+	return &Server{
+		Root:    root,
+		Options: options,
+	}
+}
+
+// Register registers the server with a gRPC service registrar
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	tailreaderpb.RegisterTailServiceServer(registrar, s)
+}
+
+// Tail streams chunks of the requested file until it ends or the client cancels
+func (s *Server) Tail(req *tailreaderpb.TailRequest, stream grpc.ServerStreamingServer[tailreaderpb.Chunk]) error {
+	path, err := s.resolve(req.GetPath())
+	if err != nil {
+		return err
+	}
+
+	options := append(append([]tailreader.Option(nil), s.Options...), requestOptions(req.GetOptions())...)
+	tr, err := tailreader.NewTailingReader(path, options...)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer tr.Close()
+
+	ctx := stream.Context()
+	stop := context.AfterFunc(ctx, func() {
+		_ = tr.Close()
+	})
+	defer stop()
+
+	chunkSize := s.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, off, err := tr.ReadAtOffset(buf)
+		if n > 0 {
+			chunk := &tailreaderpb.Chunk{
+				Offset: off,
+				Data:   append([]byte(nil), buf[:n]...),
+				Time:   timestamppb.Now(),
+			}
+			if sendErr := stream.Send(chunk); sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if errors.Is(err, tailreader.ErrClosed) && ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		} else if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return toStatus(err)
+		}
+	}
+}
+
+// resolve maps a requested path to a file below Root
+func (s *Server) resolve(name string) (string, error) {
+	if s.Root == "" {
+		return "", status.Error(codes.FailedPrecondition, "no root directory configured")
+	}
+
+	name = filepath.FromSlash(name)
+	if !filepath.IsLocal(name) {
+		return "", status.Errorf(codes.InvalidArgument, "invalid path %q", name)
+	}
+
+	return filepath.Join(s.Root, name), nil
+}
+
+// requestOptions converts the options of a request
+func requestOptions(o *tailreaderpb.TailOptions) []tailreader.Option {
+	if o == nil {
+		return nil
+	}
+
+	var waitTimeout time.Duration
+	if o.GetWaitForFileTimeout() != nil {
+		waitTimeout = o.GetWaitForFileTimeout().AsDuration()
+	}
+
+	options := []tailreader.Option{
+		tailreader.WithWaitForFile(o.GetWaitForFile(), waitTimeout),
+		tailreader.WithCloseOnDelete(o.GetCloseOnDelete()),
+		tailreader.WithCloseOnTruncate(o.GetCloseOnTruncate()),
+		tailreader.WithTimeoutsAsEOF(o.GetTreatTimeoutsAsEof()),
+		tailreader.WithStartAtEnd(o.GetStartAtEnd()),
+	}
+	if o.GetIdleTimeout() != nil {
+		options = append(options, tailreader.WithIdleTimeout(o.GetIdleTimeout().AsDuration()))
+	}
+
+	return options
+}
+
+// toStatus converts a reader error into a gRPC status error
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, fs.ErrPermission):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, tailreader.ErrInvalidOptions):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, tailreader.ErrIdleTimeout), errors.Is(err, tailreader.ErrWaitTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,110 @@
+package tailreadergrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/maurice2k/tailreader/tailreadergrpc/tailreaderpb"
+)
+
+func newTestClient(t *testing.T, server *Server) tailreaderpb.TailServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	server.Register(s)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return tailreaderpb.NewTailServiceClient(conn)
+}
+
+func TestServer_Tail(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "test.log"), []byte("Hello, World!"), 0644))
+
+	client := newTestClient(t, NewServer(dir))
+	stream, err := client.Tail(context.Background(), &tailreaderpb.TailRequest{
+		Path: "test.log",
+		Options: &tailreaderpb.TailOptions{
+			IdleTimeout:        durationpb.New(100 * time.Millisecond),
+			TreatTimeoutsAsEof: true,
+		},
+	})
+	assert.NoError(t, err)
+
+	var data []byte
+	var offsets []int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		data = append(data, chunk.GetData()...)
+		offsets = append(offsets, chunk.GetOffset())
+	}
+
+	assert.Equal(t, "Hello, World!", string(data))
+	assert.Equal(t, []int64{0}, offsets)
+}
+
+func TestServer_TailCancel(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "test.log"), []byte("Hello"), 0644))
+
+	client := newTestClient(t, NewServer(dir))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Tail(ctx, &tailreaderpb.TailRequest{Path: "test.log"})
+	assert.NoError(t, err)
+
+	chunk, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(chunk.GetData()))
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestServer_TailInvalidPath(t *testing.T) {
+	client := newTestClient(t, NewServer(t.TempDir()))
+
+	for _, path := range []string{"../test.log", "/etc/passwd", ""} {
+		stream, err := client.Tail(context.Background(), &tailreaderpb.TailRequest{Path: path})
+		assert.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), path)
+	}
+}
+
+func TestServer_TailNotFound(t *testing.T) {
+	client := newTestClient(t, NewServer(t.TempDir()))
+
+	stream, err := client.Tail(context.Background(), &tailreaderpb.TailRequest{Path: "missing.log", Options: &tailreaderpb.TailOptions{}})
+	assert.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,4 @@
+// Package tailreaderpb contains the protobuf messages and gRPC stubs of the tail service
+package tailreaderpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative tailreader.proto
@@ -0,0 +1,313 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: tailreader.proto
+
+package tailreaderpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TailRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path of the file, relative to the server's root directory
+	Path          string       `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Options       *TailOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TailRequest) Reset() {
+	*x = TailRequest{}
+	mi := &file_tailreader_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailRequest) ProtoMessage() {}
+
+func (x *TailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tailreader_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailRequest.ProtoReflect.Descriptor instead.
+func (*TailRequest) Descriptor() ([]byte, []int) {
+	return file_tailreader_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TailRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *TailRequest) GetOptions() *TailOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// TailOptions mirror the plain tailreader options
+type TailOptions struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	WaitForFile        bool                   `protobuf:"varint,1,opt,name=wait_for_file,json=waitForFile,proto3" json:"wait_for_file,omitempty"`
+	WaitForFileTimeout *durationpb.Duration   `protobuf:"bytes,2,opt,name=wait_for_file_timeout,json=waitForFileTimeout,proto3" json:"wait_for_file_timeout,omitempty"`
+	CloseOnDelete      bool                   `protobuf:"varint,3,opt,name=close_on_delete,json=closeOnDelete,proto3" json:"close_on_delete,omitempty"`
+	CloseOnTruncate    bool                   `protobuf:"varint,4,opt,name=close_on_truncate,json=closeOnTruncate,proto3" json:"close_on_truncate,omitempty"`
+	IdleTimeout        *durationpb.Duration   `protobuf:"bytes,5,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`
+	TreatTimeoutsAsEof bool                   `protobuf:"varint,6,opt,name=treat_timeouts_as_eof,json=treatTimeoutsAsEof,proto3" json:"treat_timeouts_as_eof,omitempty"`
+	StartAtEnd         bool                   `protobuf:"varint,7,opt,name=start_at_end,json=startAtEnd,proto3" json:"start_at_end,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TailOptions) Reset() {
+	*x = TailOptions{}
+	mi := &file_tailreader_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TailOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailOptions) ProtoMessage() {}
+
+func (x *TailOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_tailreader_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailOptions.ProtoReflect.Descriptor instead.
+func (*TailOptions) Descriptor() ([]byte, []int) {
+	return file_tailreader_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TailOptions) GetWaitForFile() bool {
+	if x != nil {
+		return x.WaitForFile
+	}
+	return false
+}
+
+func (x *TailOptions) GetWaitForFileTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.WaitForFileTimeout
+	}
+	return nil
+}
+
+func (x *TailOptions) GetCloseOnDelete() bool {
+	if x != nil {
+		return x.CloseOnDelete
+	}
+	return false
+}
+
+func (x *TailOptions) GetCloseOnTruncate() bool {
+	if x != nil {
+		return x.CloseOnTruncate
+	}
+	return false
+}
+
+func (x *TailOptions) GetIdleTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return nil
+}
+
+func (x *TailOptions) GetTreatTimeoutsAsEof() bool {
+	if x != nil {
+		return x.TreatTimeoutsAsEof
+	}
+	return false
+}
+
+func (x *TailOptions) GetStartAtEnd() bool {
+	if x != nil {
+		return x.StartAtEnd
+	}
+	return false
+}
+
+type Chunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Offset of the first byte of data within the file
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chunk) Reset() {
+	*x = Chunk{}
+	mi := &file_tailreader_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chunk) ProtoMessage() {}
+
+func (x *Chunk) ProtoReflect() protoreflect.Message {
+	mi := &file_tailreader_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
+func (*Chunk) Descriptor() ([]byte, []int) {
+	return file_tailreader_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Chunk) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Chunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Chunk) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_tailreader_proto protoreflect.FileDescriptor
+
+const file_tailreader_proto_rawDesc = "" +
+	"\n" +
+	"\x10tailreader.proto\x12\rtailreader.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"W\n" +
+	"\vTailRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x124\n" +
+	"\aoptions\x18\x02 \x01(\v2\x1a.tailreader.v1.TailOptionsR\aoptions\"\xe6\x02\n" +
+	"\vTailOptions\x12\"\n" +
+	"\rwait_for_file\x18\x01 \x01(\bR\vwaitForFile\x12L\n" +
+	"\x15wait_for_file_timeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x12waitForFileTimeout\x12&\n" +
+	"\x0fclose_on_delete\x18\x03 \x01(\bR\rcloseOnDelete\x12*\n" +
+	"\x11close_on_truncate\x18\x04 \x01(\bR\x0fcloseOnTruncate\x12<\n" +
+	"\fidle_timeout\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vidleTimeout\x121\n" +
+	"\x15treat_timeouts_as_eof\x18\x06 \x01(\bR\x12treatTimeoutsAsEof\x12 \n" +
+	"\fstart_at_end\x18\a \x01(\bR\n" +
+	"startAtEnd\"c\n" +
+	"\x05Chunk\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time2I\n" +
+	"\vTailService\x12:\n" +
+	"\x04Tail\x12\x1a.tailreader.v1.TailRequest\x1a\x14.tailreader.v1.Chunk0\x01B=Z;github.com/maurice2k/tailreader/tailreadergrpc/tailreaderpbb\x06proto3"
+
+var (
+	file_tailreader_proto_rawDescOnce sync.Once
+	file_tailreader_proto_rawDescData []byte
+)
+
+func file_tailreader_proto_rawDescGZIP() []byte {
+	file_tailreader_proto_rawDescOnce.Do(func() {
+		file_tailreader_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tailreader_proto_rawDesc), len(file_tailreader_proto_rawDesc)))
+	})
+	return file_tailreader_proto_rawDescData
+}
+
+var file_tailreader_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_tailreader_proto_goTypes = []any{
+	(*TailRequest)(nil),           // 0: tailreader.v1.TailRequest
+	(*TailOptions)(nil),           // 1: tailreader.v1.TailOptions
+	(*Chunk)(nil),                 // 2: tailreader.v1.Chunk
+	(*durationpb.Duration)(nil),   // 3: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_tailreader_proto_depIdxs = []int32{
+	1, // 0: tailreader.v1.TailRequest.options:type_name -> tailreader.v1.TailOptions
+	3, // 1: tailreader.v1.TailOptions.wait_for_file_timeout:type_name -> google.protobuf.Duration
+	3, // 2: tailreader.v1.TailOptions.idle_timeout:type_name -> google.protobuf.Duration
+	4, // 3: tailreader.v1.Chunk.time:type_name -> google.protobuf.Timestamp
+	0, // 4: tailreader.v1.TailService.Tail:input_type -> tailreader.v1.TailRequest
+	2, // 5: tailreader.v1.TailService.Tail:output_type -> tailreader.v1.Chunk
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_tailreader_proto_init() }
+func file_tailreader_proto_init() {
+	if File_tailreader_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tailreader_proto_rawDesc), len(file_tailreader_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tailreader_proto_goTypes,
+		DependencyIndexes: file_tailreader_proto_depIdxs,
+		MessageInfos:      file_tailreader_proto_msgTypes,
+	}.Build()
+	File_tailreader_proto = out.File
+	file_tailreader_proto_goTypes = nil
+	file_tailreader_proto_depIdxs = nil
+}
@@ -0,0 +1,132 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: tailreader.proto
+
+package tailreaderpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TailService_Tail_FullMethodName = "/tailreader.v1.TailService/Tail"
+)
+
+// TailServiceClient is the client API for TailService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TailService streams the contents of files as they grow
+type TailServiceClient interface {
+	// Tail streams chunks of the requested file until it ends or the client
+	// cancels the call
+	Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Chunk], error)
+}
+
+type tailServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTailServiceClient(cc grpc.ClientConnInterface) TailServiceClient {
+	return &tailServiceClient{cc}
+}
+
+func (c *tailServiceClient) Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Chunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TailService_ServiceDesc.Streams[0], TailService_Tail_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TailRequest, Chunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TailService_TailClient = grpc.ServerStreamingClient[Chunk]
+
+// TailServiceServer is the server API for TailService service.
+// All implementations must embed UnimplementedTailServiceServer
+// for forward compatibility.
+//
+// TailService streams the contents of files as they grow
+type TailServiceServer interface {
+	// Tail streams chunks of the requested file until it ends or the client
+	// cancels the call
+	Tail(*TailRequest, grpc.ServerStreamingServer[Chunk]) error
+	mustEmbedUnimplementedTailServiceServer()
+}
+
+// UnimplementedTailServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTailServiceServer struct{}
+
+func (UnimplementedTailServiceServer) Tail(*TailRequest, grpc.ServerStreamingServer[Chunk]) error {
+	return status.Error(codes.Unimplemented, "method Tail not implemented")
+}
+func (UnimplementedTailServiceServer) mustEmbedUnimplementedTailServiceServer() {}
+func (UnimplementedTailServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeTailServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TailServiceServer will
+// result in compilation errors.
+type UnsafeTailServiceServer interface {
+	mustEmbedUnimplementedTailServiceServer()
+}
+
+func RegisterTailServiceServer(s grpc.ServiceRegistrar, srv TailServiceServer) {
+	// If the following call panics, it indicates UnimplementedTailServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TailService_ServiceDesc, srv)
+}
+
+func _TailService_Tail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TailServiceServer).Tail(m, &grpc.GenericServerStream[TailRequest, Chunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TailService_TailServer = grpc.ServerStreamingServer[Chunk]
+
+// TailService_ServiceDesc is the grpc.ServiceDesc for TailService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TailService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tailreader.v1.TailService",
+	HandlerType: (*TailServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tail",
+			Handler:       _TailService_Tail_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tailreader.proto",
+}
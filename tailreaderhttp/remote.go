@@ -0,0 +1,275 @@
+package tailreaderhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// DefaultRequestTimeout limits requests if FS.RequestTimeout is 0
+const DefaultRequestTimeout = 30 * time.Second
+
+var ErrRangeNotSupported = fmt.Errorf("server does not support range requests")
+var errUnknownSize = fmt.Errorf("server did not report the file size")
+
+// FS is a tailreader.FS for files served over HTTP; names are URLs
+//
+// Stat issues a HEAD request, taking Content-Length as the size and
+// Last-Modified as the modification time. Reads fetch the bytes following the
+// reader's offset with Range requests. The ETag (or Last-Modified if there is
+// none) is reported as the file's version, so a file rewritten with the same
+// size is read again from the start (see tailreader.VersionedFileInfo);
+// shrinking files are handled like truncated local files.
+type FS struct {
+	// Client is used for all requests; nil uses http.DefaultClient
+	Client *http.Client
+
+	// Header is added to every request, e.g. for authorization
+	Header http.Header
+
+	// RequestTimeout limits each request, including reading its body; 0 uses
+	// DefaultRequestTimeout, a negative timeout means no limit
+	RequestTimeout time.Duration
+}
+
+// NewRemoteTailingReader creates a reader for the file at rawURL
+//
+// Without options, tailreader.DefaultOptions are used; the poll interval
+// (tailreader.WithPollInterval) sets how often the server is asked for changes.
+func NewRemoteTailingReader(rawURL string, options ...tailreader.Option) (*tailreader.TailingReader, error) {
+	if len(options) == 0 {
+		options = tailreader.DefaultOptions
+	}
+	options = append(options[:len(options):len(options)], tailreader.WithFS(&FS{}))
+
+	return tailreader.NewTailingReader(rawURL, options...)
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	resp, err := f.do(http.MethodHead, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	err = statusError("stat", name, resp.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContentLength < 0 {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: errUnknownSize}
+	}
+
+	info := &fileInfo{
+		name:    name,
+		size:    resp.ContentLength,
+		version: resp.Header.Get("ETag"),
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		info.modTime, _ = http.ParseTime(lastModified)
+		if info.version == "" {
+			info.version = lastModified
+		}
+	}
+
+	return info, nil
+}
+
+func (f *FS) OpenFile(name string, flag int) (fs.File, error) {
+	_, err := url.Parse(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &remoteFile{fs: f, name: name}, nil
+}
+
+// do sends a request; its context is cancelled once the response body is closed
+func (f *FS) do(method string, name string, header http.Header) (*http.Response, error) {
+	ctx, cancel := f.context()
+	req, err := http.NewRequestWithContext(ctx, method, name, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for key, values := range f.Header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (f *FS) context() (context.Context, context.CancelFunc) {
+	timeout := f.RequestTimeout
+	if timeout == 0 {
+		timeout = DefaultRequestTimeout
+	}
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// cancelBody cancels the context of its request once it's closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// statusError maps unsuccessful HTTP status codes to file system errors
+func statusError(op string, name string, code int) error {
+	switch {
+	case code >= 200 && code < 300:
+		return nil
+	case code == http.StatusNotFound || code == http.StatusGone:
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+	default:
+		return &fs.PathError{Op: op, Path: name, Err: fmt.Errorf("unexpected status %d", code)}
+	}
+}
+
+// remoteFile reads a file served over HTTP
+//
+// The body of a Range request is kept open across reads until it's consumed.
+type remoteFile struct {
+	fs     *FS
+	name   string
+	offset int64
+	body   io.ReadCloser
+}
+
+func (f *remoteFile) Stat() (fs.FileInfo, error) {
+	return f.fs.Stat(f.name)
+}
+
+func (f *remoteFile) Read(p []byte) (int, error) {
+	if f.body == nil {
+		err := f.request()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	if err != nil {
+		f.closeBody()
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+	}
+
+	return n, err
+}
+
+// request starts a Range request for the data following offset
+func (f *remoteFile) request() error {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-", f.offset)}}
+	resp, err := f.fs.do(http.MethodGet, f.name, header)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// nothing beyond offset (yet)
+		resp.Body.Close()
+		return io.EOF
+	case resp.StatusCode == http.StatusOK && f.offset > 0:
+		resp.Body.Close()
+		return &fs.PathError{Op: "read", Path: f.name, Err: ErrRangeNotSupported}
+	}
+
+	err = statusError("read", f.name, resp.StatusCode)
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+
+	f.body = resp.Body
+	return nil
+}
+
+func (f *remoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	default:
+		return f.offset, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return f.offset, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset != f.offset {
+		f.closeBody()
+		f.offset = offset
+	}
+	return offset, nil
+}
+
+func (f *remoteFile) Close() error {
+	f.closeBody()
+	return nil
+}
+
+func (f *remoteFile) closeBody() {
+	if f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+}
+
+// fileInfo describes a file served over HTTP
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	version string
+}
+
+func (i *fileInfo) Name() string {
+	u, err := url.Parse(i.name)
+	if err != nil {
+		return i.name
+	}
+	return path.Base(u.Path)
+}
+
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) Mode() fs.FileMode  { return 0444 }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return false }
+func (i *fileInfo) Sys() any           { return nil }
+func (i *fileInfo) Version() string    { return i.version }
@@ -0,0 +1,135 @@
+package tailreaderhttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+// remoteFileServer serves a single file whose content can be changed
+type remoteFileServer struct {
+	mu       sync.Mutex
+	data     []byte
+	revision int
+}
+
+func (s *remoteFileServer) set(data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = []byte(data)
+	s.revision++
+}
+
+func (s *remoteFileServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	data := s.data
+	revision := s.revision
+	s.mu.Unlock()
+
+	if data == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, revision))
+	http.ServeContent(w, req, "test.log", time.Time{}, bytes.NewReader(data))
+}
+
+func TestRemoteTailingReader(t *testing.T) {
+	files := &remoteFileServer{}
+	files.set("Hello,")
+	server := httptest.NewServer(files)
+	defer server.Close()
+
+	tr, err := NewRemoteTailingReader(server.URL+"/test.log",
+		tailreader.WithPollInterval(10*time.Millisecond),
+		tailreader.WithIdleTimeout(500*time.Millisecond),
+		tailreader.WithTimeoutsAsEOF(true),
+	)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 64)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello,", string(buf[:n]))
+
+	files.set("Hello, World!")
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " World!", string(buf[:n]))
+
+	// rewritten with the same size
+	files.set("Goodbye World")
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Goodbye World", string(buf[:n]))
+
+	// truncated
+	files.set("Hi")
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi", string(buf[:n]))
+
+	_, err = tr.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFS_Stat(t *testing.T) {
+	files := &remoteFileServer{}
+	server := httptest.NewServer(files)
+	defer server.Close()
+
+	fsys := &FS{}
+	_, err := fsys.Stat(server.URL + "/test.log")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	files.set("Hello")
+	info, err := fsys.Stat(server.URL + "/test.log")
+	assert.NoError(t, err)
+	assert.Equal(t, "test.log", info.Name())
+	assert.Equal(t, int64(5), info.Size())
+	assert.Equal(t, `"1"`, info.(tailreader.VersionedFileInfo).Version())
+}
+
+func TestFS_RangeNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "Hello, World!")
+	}))
+	defer server.Close()
+
+	file, err := (&FS{}).OpenFile(server.URL, 0)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.(io.Seeker).Seek(7, io.SeekStart)
+	assert.NoError(t, err)
+
+	_, err = file.Read(make([]byte, 64))
+	assert.ErrorIs(t, err, ErrRangeNotSupported)
+}
+
+func TestFS_RequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// a hung server doesn't block the reader forever
+	start := time.Now()
+	_, err := (&FS{RequestTimeout: 50 * time.Millisecond}).Stat(server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,137 @@
+// Package tailreaderhttp serves tailed files over HTTP and tails files served over HTTP.
+package tailreaderhttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// DefaultHeartbeatInterval is the interval of SSE heartbeats if none is set
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// SSEHandler streams a tailed file to HTTP clients as server-sent events
+//
+// Every client gets its own reader. Each event carries one chunk of data as
+// returned by Read (which is not necessarily a complete line); newlines within
+// the data are preserved using multi-line data fields. When the reader ends,
+// an "eof" event is sent, or an "error" event with the error message.
+// Heartbeat comments keep idle connections alive, and the reader is closed as
+// soon as the client disconnects.
+type SSEHandler struct {
+	Path    string
+	Options []tailreader.Option
+
+	// HeartbeatInterval is the interval of heartbeat comments; 0 uses DefaultHeartbeatInterval
+	HeartbeatInterval time.Duration
+}
+
+// NewSSEHandler returns an SSEHandler tailing path with the given options
+func NewSSEHandler(path string, options ...tailreader.Option) *SSEHandler {
+	return &SSEHandler{
+		Path:    path,
+		Options: options,
+	}
+}
+
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	tr, err := tailreader.NewTailingReader(h.Path, h.Options...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tr.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := req.Context()
+	chunks := make(chan []byte)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(chunks)
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := tr.Read(buf)
+			if n > 0 {
+				select {
+				case chunks <- append([]byte(nil), buf[:n]...):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	interval := h.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case data, ok := <-chunks:
+			if !ok {
+				err = <-errc
+				if errors.Is(err, io.EOF) {
+					_ = writeEvent(w, "eof", nil)
+				} else {
+					_ = writeEvent(w, "error", []byte(err.Error()))
+				}
+				flusher.Flush()
+				return
+			}
+
+			err = writeEvent(w, "", data)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case <-ctx.Done():
+			// the deferred Close stops the reading goroutine
+			return
+		}
+
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+var newlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func writeEvent(w io.Writer, event string, data []byte) error {
+	var sb strings.Builder
+	if event != "" {
+		fmt.Fprintf(&sb, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(newlines.Replace(string(data)), "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,39 @@
+package tailreaderhttp
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEHandler(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello,\nWorld!")
+	assert.NoError(t, err)
+
+	handler := NewSSEHandler(file.Name(), tailreader.WithIdleTimeout(100*time.Millisecond), tailreader.WithTimeoutsAsEOF(true))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	assert.Equal(t, []string{"data: Hello,", "data: World!", "", "event: eof", "data: ", ""}, lines)
+}
@@ -0,0 +1,161 @@
+package tailreaderhttp
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// WebSocket message types as defined in RFC 6455
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+)
+
+// closeNormal is the close code for a normal closure
+const closeNormal = 1000
+
+// WebSocketConn is the part of a WebSocket connection used by StreamWebSocket
+//
+// It is satisfied by *websocket.Conn of github.com/gorilla/websocket.
+type WebSocketConn interface {
+	ReadMessage() (messageType int, data []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+}
+
+// WebSocketControl is a control message sent by the client as a text message
+//
+//	{"action": "pause"}
+//	{"action": "resume"}
+//	{"action": "seek", "offset": 0, "whence": 0}
+//
+// Seek uses the semantics of io.Seeker.
+type WebSocketControl struct {
+	Action string `json:"action"`
+	Offset int64  `json:"offset,omitempty"`
+	Whence int    `json:"whence,omitempty"`
+}
+
+// chunk is data read at offset
+type chunk struct {
+	offset int64
+	data   []byte
+}
+
+// StreamWebSocket streams tr to conn until the reader ends, the connection
+// fails or ctx is done
+//
+// Every chunk returned by Read is sent as a message of messageType
+// (TextMessage or BinaryMessage). Chunks are only read as fast as they can be
+// written, so slow clients don't cause buffering; while paused, reading stops
+// entirely. The reader is closed when StreamWebSocket returns, while closing
+// conn is left to the caller. It returns nil if the reader ended with io.EOF,
+// in which case a close message is sent.
+func StreamWebSocket(ctx context.Context, conn WebSocketConn, tr *tailreader.TailingReader, messageType int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer tr.Close()
+
+	chunks := make(chan chunk)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(chunks)
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, off, err := tr.ReadAtOffset(buf)
+			if n > 0 {
+				c := chunk{offset: off, data: append([]byte(nil), buf[:n]...)}
+				select {
+				case chunks <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	controls := make(chan WebSocketControl)
+	connErrc := make(chan error, 1)
+	go func() {
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				connErrc <- err
+				return
+			}
+
+			var control WebSocketControl
+			if typ != TextMessage || json.Unmarshal(data, &control) != nil {
+				// ignore anything that isn't a control message
+				continue
+			}
+
+			select {
+			case controls <- control:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	next := tr.Offset()
+	paused := false
+
+	for {
+		// not receiving chunks while paused blocks the reading goroutine
+		in := chunks
+		if paused {
+			in = nil
+		}
+
+		select {
+		case c, ok := <-in:
+			if !ok {
+				err := <-errc
+				if errors.Is(err, io.EOF) {
+					msg := binary.BigEndian.AppendUint16(nil, closeNormal)
+					return conn.WriteMessage(CloseMessage, msg)
+				}
+				return err
+			}
+
+			if c.offset != next {
+				// read before a seek
+				continue
+			}
+			next += int64(len(c.data))
+
+			err := conn.WriteMessage(messageType, c.data)
+			if err != nil {
+				return err
+			}
+		case control := <-controls:
+			switch control.Action {
+			case "pause":
+				paused = true
+			case "resume":
+				paused = false
+			case "seek":
+				offset, err := tr.Seek(control.Offset, control.Whence)
+				if err == nil {
+					next = offset
+				}
+			}
+		case err := <-connErrc:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
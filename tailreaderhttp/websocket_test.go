@@ -0,0 +1,65 @@
+package tailreaderhttp
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+type message struct {
+	typ  int
+	data []byte
+}
+
+// fakeConn is a WebSocketConn backed by channels
+type fakeConn struct {
+	in  chan message
+	out chan message
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	m, ok := <-c.in
+	if !ok {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return m.typ, m.data, nil
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	c.out <- message{messageType, data}
+	return nil
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := tailreader.NewTailingReader(file.Name(), tailreader.WithIdleTimeout(500*time.Millisecond), tailreader.WithTimeoutsAsEOF(true))
+
+	conn := &fakeConn{in: make(chan message), out: make(chan message)}
+	errc := make(chan error)
+	go func() {
+		errc <- StreamWebSocket(context.Background(), conn, tr, TextMessage)
+	}()
+
+	m := <-conn.out
+	assert.Equal(t, TextMessage, m.typ)
+	assert.Equal(t, "Hello, World!", string(m.data))
+
+	conn.in <- message{TextMessage, []byte(`{"action": "seek", "offset": 7}`)}
+
+	m = <-conn.out
+	assert.Equal(t, "World!", string(m.data))
+
+	m = <-conn.out
+	assert.Equal(t, CloseMessage, m.typ)
+	assert.NoError(t, <-errc)
+}
@@ -0,0 +1,412 @@
+package tailreadernet
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+const (
+	// DefaultFluentBatchSize is the maximum number of records sent at once if none is set
+	DefaultFluentBatchSize = 1000
+
+	// DefaultFluentAckTimeout is how long to wait for an ack if no timeout is set
+	DefaultFluentAckTimeout = 30 * time.Second
+)
+
+var errFluentAck = fmt.Errorf("fluent: unexpected ack")
+
+// FluentSink is a tailreader.Sink sending records to Fluentd or Fluent Bit using the forward protocol
+//
+// Records are sent in Forward mode with the tag Tag, each as a map with the
+// keys "message", "path", "level" (if the record has one, see
+// tailreader.WithLevelExtractor) and the record's labels (see tailreader.WithLabels). Every message requests an ack, and Flush only
+// returns once the aggregator acknowledged it, so offsets committed after
+// flushing (see tailreader.Manager) only cover records that were received.
+// If sending fails, the connection is closed and the batch is sent again by
+// the next Flush, so records may be received twice.
+type FluentSink struct {
+	Network string // "tcp" or "unix"; empty uses "tcp"
+	Address string
+	Tag     string
+
+	// BatchSize is the maximum number of records sent at once; 0 uses DefaultFluentBatchSize
+	BatchSize int
+
+	// AckTimeout limits sending a batch and waiting for its ack; 0 uses DefaultFluentAckTimeout
+	AckTimeout time.Duration
+
+	// Dial is used to connect; nil uses a net.Dialer
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	conn    net.Conn
+	reader  *bufio.Reader
+	entries []byte // msgpack encoded entries of the current batch
+	size    int    // number of entries in the current batch
+}
+
+func (s *FluentSink) Write(ctx context.Context, rec tailreader.Record) error {
+	labels := maps.Clone(rec.Labels)
+	delete(labels, "message")
+	delete(labels, "path")
+	if rec.Level != tailreader.LevelNone {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels["level"] = rec.Level.String()
+	}
+
+	// [time, {"message": data, "path": path, "level": level, labels...}]
+	start := len(s.entries)
+	s.entries = appendMsgpackArray(s.entries, 2)
+	s.entries = appendMsgpackTime(s.entries, rec.EventTime())
+	s.entries = appendMsgpackMap(s.entries, 2+len(labels))
+	s.entries = appendMsgpackString(s.entries, "message")
+	s.entries = appendMsgpackString(s.entries, string(rec.Data))
+	s.entries = appendMsgpackString(s.entries, "path")
+	s.entries = appendMsgpackString(s.entries, rec.Path)
+	for _, key := range sortedKeys(labels) {
+		s.entries = appendMsgpackString(s.entries, key)
+		s.entries = appendMsgpackString(s.entries, labels[key])
+	}
+	s.size++
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultFluentBatchSize
+	}
+	if s.size >= batchSize {
+		err := s.Flush(ctx)
+		if err != nil {
+			// cut the entry of rec off the buffered entries
+			s.entries = s.entries[:start]
+			s.size--
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FluentSink) Flush(ctx context.Context) error {
+	if s.size == 0 {
+		return nil
+	}
+
+	chunk, err := newChunkID()
+	if err != nil {
+		return err
+	}
+
+	// [tag, [entries...], {"chunk": chunk}]
+	msg := appendMsgpackArray(nil, 3)
+	msg = appendMsgpackString(msg, s.Tag)
+	msg = appendMsgpackArray(msg, s.size)
+	msg = append(msg, s.entries...)
+	msg = appendMsgpackMap(msg, 1)
+	msg = appendMsgpackString(msg, "chunk")
+	msg = appendMsgpackString(msg, chunk)
+
+	err = s.send(ctx, msg, chunk)
+	if err != nil {
+		// keep the batch to send it again with the next flush
+		s.close()
+		return err
+	}
+
+	s.entries = s.entries[:0]
+	s.size = 0
+	return nil
+}
+
+// send writes msg and waits for the ack of chunk
+func (s *FluentSink) send(ctx context.Context, msg []byte, chunk string) error {
+	err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	timeout := s.AckTimeout
+	if timeout <= 0 {
+		timeout = DefaultFluentAckTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	err = s.conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.conn.Write(msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := decodeMsgpack(s.reader)
+	if err != nil {
+		return err
+	}
+	if m, ok := resp.(map[string]any); !ok || m["ack"] != chunk {
+		return fmt.Errorf("%w: %v", errFluentAck, resp)
+	}
+	return nil
+}
+
+func (s *FluentSink) connect(ctx context.Context) error {
+	if s.conn != nil {
+		return nil
+	}
+
+	network := s.Network
+	if network == "" {
+		network = "tcp"
+	}
+
+	dial := s.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	conn, err := dial(ctx, network, s.Address)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	return nil
+}
+
+// Close closes the connection to the aggregator
+func (s *FluentSink) Close() error {
+	s.close()
+	return nil
+}
+
+func (s *FluentSink) close() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		s.reader = nil
+	}
+}
+
+// newChunkID returns a random chunk ID to be acknowledged
+func newChunkID() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(id), nil
+}
+
+func appendMsgpackArray(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func appendMsgpackMap(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+// appendMsgpackTime appends t as the forward protocol's EventTime (ext type 0)
+func appendMsgpackTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// decodeMsgpack decodes a msgpack value
+//
+// Maps are decoded as map[string]any (keys must be strings), arrays as []any,
+// strings as string, binary and ext data as []byte and integers as int64.
+// Floats are not supported.
+func decodeMsgpack(r *bufio.Reader) (any, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t <= 0x7f:
+		return int64(t), nil
+	case t >= 0xe0:
+		return int64(int8(t)), nil
+	case t&0xf0 == 0x80:
+		return decodeMsgpackMap(r, int(t&0x0f))
+	case t&0xf0 == 0x90:
+		return decodeMsgpackArray(r, int(t&0x0f))
+	case t&0xe0 == 0xa0:
+		data, err := readMsgpackData(r, int(t&0x1f))
+		return string(data), err
+	}
+
+	switch t {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return t == 0xc3, nil
+	case 0xc4, 0xd9:
+		data, err := readMsgpackLength(r, 1)
+		if t == 0xd9 {
+			return string(data), err
+		}
+		return data, err
+	case 0xc5, 0xda:
+		data, err := readMsgpackLength(r, 2)
+		if t == 0xda {
+			return string(data), err
+		}
+		return data, err
+	case 0xc6, 0xdb:
+		data, err := readMsgpackLength(r, 4)
+		if t == 0xdb {
+			return string(data), err
+		}
+		return data, err
+	case 0xcc, 0xcd, 0xce, 0xcf, 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << ((t - 0xcc) % 4)
+		data, err := readMsgpackData(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return msgpackInt(data, t >= 0xd0), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		// fixext: type and 1, 2, 4, 8 or 16 bytes of data
+		return readMsgpackData(r, 1+1<<(t-0xd4))
+	case 0xdc:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackArray(r, n)
+	case 0xdd:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackArray(r, n)
+	case 0xde:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackMap(r, n)
+	case 0xdf:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackMap(r, n)
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", t)
+}
+
+func decodeMsgpackArray(r *bufio.Reader, n int) ([]any, error) {
+	values := make([]any, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		v, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func decodeMsgpackMap(r *bufio.Reader, n int) (map[string]any, error) {
+	m := make(map[string]any, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, errors.New("msgpack: map key is not a string")
+		}
+
+		m[key], err = decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// readMsgpackLength reads data preceded by its length of size bytes
+func readMsgpackLength(r *bufio.Reader, size int) ([]byte, error) {
+	n, err := readMsgpackUint(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return readMsgpackData(r, n)
+}
+
+func readMsgpackUint(r *bufio.Reader, size int) (int, error) {
+	data, err := readMsgpackData(r, size)
+	if err != nil {
+		return 0, err
+	}
+	return int(msgpackInt(data, false)), nil
+}
+
+func readMsgpackData(r *bufio.Reader, n int) ([]byte, error) {
+	data := make([]byte, n)
+	_, err := io.ReadFull(r, data)
+	return data, err
+}
+
+// msgpackInt decodes a big endian integer of 1, 2, 4 or 8 bytes
+func msgpackInt(data []byte, signed bool) int64 {
+	var u uint64
+	for _, b := range data {
+		u = u<<8 | uint64(b)
+	}
+	if signed {
+		shift := 64 - 8*len(data)
+		return int64(u<<shift) >> shift
+	}
+	return int64(u)
+}
@@ -0,0 +1,111 @@
+package tailreadernet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFluentSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	messages := make(chan []any)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := decodeMsgpack(r)
+			if err != nil {
+				return
+			}
+			messages <- msg.([]any)
+
+			chunk := msg.([]any)[2].(map[string]any)["chunk"].(string)
+			ack := appendMsgpackMap(nil, 1)
+			ack = appendMsgpackString(ack, "ack")
+			ack = appendMsgpackString(ack, chunk)
+			_, _ = conn.Write(ack)
+		}
+	}()
+
+	s := &FluentSink{Address: ln.Addr().String(), Tag: "app.logs"}
+	defer s.Close()
+
+	ts := time.Unix(1700000000, 5)
+	err = s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte("Hello"), Time: ts})
+	assert.NoError(t, err)
+
+	flushed := make(chan error)
+	go func() {
+		flushed <- s.Flush(context.Background())
+	}()
+
+	msg := <-messages
+	assert.NoError(t, <-flushed)
+
+	assert.Equal(t, "app.logs", msg[0])
+	assert.Equal(t, []any{[]any{
+		[]byte{0x00, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x00, 0x05},
+		map[string]any{"message": "Hello", "path": "/var/log/app.log"},
+	}}, msg[1])
+
+	// nothing left to send
+	assert.NoError(t, s.Flush(context.Background()))
+}
+
+func TestFluentSinkWithoutAck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			// never acknowledges
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	s := &FluentSink{Address: ln.Addr().String(), Tag: "app.logs", AckTimeout: 100 * time.Millisecond}
+	defer s.Close()
+
+	err = s.Write(context.Background(), tailreader.Record{Data: []byte("Hello"), Time: time.Now()})
+	assert.NoError(t, err)
+	assert.Error(t, s.Flush(context.Background()))
+	assert.Equal(t, 1, s.size)
+}
+
+func TestDecodeMsgpack(t *testing.T) {
+	var b []byte
+	b = appendMsgpackArray(b, 20)
+	for i := 0; i < 20; i++ {
+		b = appendMsgpackString(b, string(make([]byte, i*20)))
+	}
+	b = append(b, 0xd0, 0xff, 0xcd, 0x01, 0x00, 0xc3)
+
+	r := bufio.NewReader(bytes.NewReader(b))
+	v, err := decodeMsgpack(r)
+	assert.NoError(t, err)
+	assert.Len(t, v, 20)
+	assert.Len(t, v.([]any)[19], 380)
+
+	for _, expected := range []any{int64(-1), int64(256), true} {
+		v, err = decodeMsgpack(r)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+}
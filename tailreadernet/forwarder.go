@@ -0,0 +1,203 @@
+// Package tailreadernet forwards tailed files to network endpoints.
+package tailreadernet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+
+	// maxDatagramSize is the maximum payload of a UDP datagram
+	maxDatagramSize = 65507
+)
+
+// Forwarder ships tailed data to a TCP or UDP endpoint
+//
+// If the connection fails, it reconnects with exponential backoff and resends
+// the data that couldn't be written, so nothing is skipped (although data may
+// be sent twice if a write failed after it was partially transmitted).
+type Forwarder struct {
+	Network string // "tcp", "udp" or any other network supported by Dial
+	Address string
+
+	// Lines enables newline framing: only complete lines are forwarded, each
+	// terminated by a newline; for datagram networks, each line is sent as
+	// a separate datagram. Otherwise data is forwarded as it is read.
+	Lines bool
+
+	// MinBackoff and MaxBackoff limit the delay between reconnects; 0 uses the defaults
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Dial is used to connect; nil uses a net.Dialer
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	conn net.Conn
+}
+
+// Forward sends everything read from tr until the reader ends or ctx is done
+//
+// The reader is closed when Forward returns. It returns nil if the reader
+// ended with io.EOF.
+func (f *Forwarder) Forward(ctx context.Context, tr *tailreader.TailingReader) error {
+	if f.Lines {
+		return f.forward(ctx, tr, f.lines(tr))
+	}
+	return f.forward(ctx, tr, f.chunks(tr))
+}
+
+// forward sends everything returned by next, which reads from tr
+func (f *Forwarder) forward(ctx context.Context, tr *tailreader.TailingReader, next func() ([]byte, error)) error {
+	defer tr.Close()
+	defer f.close()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = tr.Close()
+	})
+	defer stop()
+
+	for {
+		data, err := next()
+		if errors.Is(err, tailreader.ErrClosed) && ctx.Err() != nil {
+			return ctx.Err()
+		} else if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		err = f.send(ctx, data)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (f *Forwarder) chunks(tr *tailreader.TailingReader) func() ([]byte, error) {
+	buf := make([]byte, 32*1024)
+	return func() ([]byte, error) {
+		for {
+			n, err := tr.Read(buf)
+			if n > 0 {
+				return buf[:n], nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (f *Forwarder) lines(tr *tailreader.TailingReader) func() ([]byte, error) {
+	var options []tailreader.RecordOption
+	if isDatagram(f.Network) {
+		options = append(options, tailreader.WithMaxRecordSize(maxDatagramSize-1))
+	}
+
+	rr := tailreader.NewRecordReader(tr, options...)
+	return func() ([]byte, error) {
+		rec, err := rr.ReadRecord()
+		if err != nil {
+			return nil, err
+		}
+		return append(rec.Data, '\n'), nil
+	}
+}
+
+// send writes data, reconnecting until it succeeds or ctx is done
+func (f *Forwarder) send(ctx context.Context, data []byte) error {
+	backoff := time.Duration(0)
+	for {
+		err := f.connect(ctx)
+		if err == nil {
+			_, err = f.conn.Write(data)
+			if err == nil {
+				return nil
+			}
+			f.close()
+		}
+
+		backoff = f.nextBackoff(backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *Forwarder) connect(ctx context.Context) error {
+	if f.conn != nil {
+		return nil
+	}
+
+	dial := f.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	conn, err := dial(ctx, f.Network, f.Address)
+	if err != nil {
+		return err
+	}
+
+	f.conn = conn
+	return nil
+}
+
+func (f *Forwarder) close() {
+	if f.conn != nil {
+		_ = f.conn.Close()
+		f.conn = nil
+	}
+}
+
+func (f *Forwarder) nextBackoff(backoff time.Duration) time.Duration {
+	return nextBackoff(backoff, f.MinBackoff, f.MaxBackoff)
+}
+
+// nextBackoff doubles backoff within the limits; 0 limits use the defaults
+func nextBackoff(backoff time.Duration, minBackoff time.Duration, maxBackoff time.Duration) time.Duration {
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	backoff *= 2
+	if backoff < minBackoff {
+		backoff = minBackoff
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+func isDatagram(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+	return false
+}
+
+// sortedKeys returns the keys of labels in sorted order
+func sortedKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package tailreadernet
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwarder_ForwardLinesOverTCP(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	_, err = file.WriteString("Hello, World!\nHello again!\nincomplete")
+	assert.NoError(t, err)
+
+	tr, _ := tailreader.NewTailingReader(file.Name(), tailreader.WithIdleTimeout(200*time.Millisecond), tailreader.WithTimeoutsAsEOF(true))
+
+	f := &Forwarder{Network: "tcp", Address: ln.Addr().String(), Lines: true}
+	errc := make(chan error)
+	go func() {
+		errc <- f.Forward(context.Background(), tr)
+	}()
+
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	assert.NoError(t, <-errc)
+	assert.Equal(t, []string{"Hello, World!", "Hello again!", "incomplete"}, lines)
+}
+
+func TestForwarder_ForwardReconnects(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	_, err := file.WriteString("Hello, World!")
+	assert.NoError(t, err)
+
+	tr, _ := tailreader.NewTailingReader(file.Name())
+
+	// the endpoint isn't available at first
+	attempts := 0
+	client, server := net.Pipe()
+	f := &Forwarder{
+		Network:    "tcp",
+		MinBackoff: time.Millisecond,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, &net.OpError{Op: "dial"}
+			}
+			return client, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- f.Forward(ctx, tr)
+	}()
+
+	buf := make([]byte, 128)
+	n, err := server.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(buf[:n]))
+	assert.Equal(t, 3, attempts)
+
+	cancel()
+	assert.ErrorIs(t, <-errc, context.Canceled)
+}
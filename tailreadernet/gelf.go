@@ -0,0 +1,222 @@
+package tailreadernet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/maurice2k/tailreader"
+)
+
+const (
+	// DefaultGELFChunkSize is the maximum size of a UDP datagram if none is set
+	DefaultGELFChunkSize = 1420
+
+	// gelfMaxChunks is the maximum number of chunks of a message
+	gelfMaxChunks = 128
+
+	// gelfChunkHeaderSize is the size of the header of every chunk
+	gelfChunkHeaderSize = 12
+)
+
+var errGELFTooLarge = fmt.Errorf("gelf: message exceeds %d chunks", gelfMaxChunks)
+
+// GELFSink is a tailreader.Sink sending records as GELF messages to Graylog
+//
+// The record's data is sent as short_message, its path as the additional
+// field _path and its labels (see tailreader.WithLabels) as additional fields
+// prefixed with an underscore. Over UDP, every message is sent as separate datagram, split
+// into chunks if it doesn't fit; over TCP, messages are delimited by null
+// bytes and sent when the Manager flushes the sink.
+type GELFSink struct {
+	Network string // "udp" or "tcp"; empty uses "udp"
+	Address string
+	Host    string // defaults to os.Hostname()
+
+	// Level is the syslog severity of messages whose records have no level (see
+	// tailreader.WithLevelExtractor); as the zero value, Emergency isn't sent
+	Level Severity
+
+	// Compress enables gzip compression of UDP messages
+	Compress bool
+
+	// ChunkSize is the maximum size of a UDP datagram; 0 uses DefaultGELFChunkSize
+	ChunkSize int
+
+	// Dial is used to connect; nil uses a net.Dialer
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	conn   net.Conn
+	writer *bufio.Writer // buffers messages over TCP
+}
+
+// gelfMessage is a GELF 1.1 message
+type gelfMessage struct {
+	Version      string  `json:"version"`
+	Host         string  `json:"host"`
+	ShortMessage string  `json:"short_message"`
+	Timestamp    float64 `json:"timestamp"`
+	Level        int     `json:"level,omitempty"`
+	Path         string  `json:"_path,omitempty"`
+}
+
+func (s *GELFSink) Write(ctx context.Context, rec tailreader.Record) error {
+	host := s.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	msg, err := json.Marshal(gelfMessage{
+		Version:      "1.1",
+		Host:         host,
+		ShortMessage: string(rec.Data),
+		Timestamp:    float64(rec.EventTime().UnixMicro()) / 1e6,
+		Level:        int(severityOf(rec.Level, s.Level)),
+		Path:         rec.Path,
+	})
+	if err != nil {
+		return err
+	}
+	msg = appendGELFFields(msg, rec.Labels)
+
+	err = s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.writer != nil {
+		_, err = s.writer.Write(append(msg, 0))
+	} else {
+		err = s.sendDatagrams(msg)
+	}
+	if err != nil {
+		s.close()
+	}
+	return err
+}
+
+func (s *GELFSink) Flush(ctx context.Context) error {
+	if s.writer == nil {
+		return nil
+	}
+
+	err := s.writer.Flush()
+	if err != nil {
+		s.close()
+	}
+	return err
+}
+
+// appendGELFFields adds labels as additional fields to the JSON object msg
+func appendGELFFields(msg []byte, labels map[string]string) []byte {
+	if len(labels) == 0 {
+		return msg
+	}
+
+	msg = msg[:len(msg)-1] // strip the closing brace
+	for _, key := range sortedKeys(labels) {
+		if key == "path" || key == "id" {
+			// reserved
+			continue
+		}
+		name, _ := json.Marshal("_" + key)
+		value, _ := json.Marshal(labels[key])
+		msg = append(msg, ',')
+		msg = append(msg, name...)
+		msg = append(msg, ':')
+		msg = append(msg, value...)
+	}
+	return append(msg, '}')
+}
+
+// sendDatagrams sends msg over UDP, compressed and chunked as configured
+func (s *GELFSink) sendDatagrams(msg []byte) error {
+	if s.Compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(msg)
+		_ = zw.Close()
+		msg = buf.Bytes()
+	}
+
+	chunkSize := s.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultGELFChunkSize
+	}
+	if len(msg) <= chunkSize {
+		_, err := s.conn.Write(msg)
+		return err
+	}
+
+	dataSize := chunkSize - gelfChunkHeaderSize
+	count := (len(msg) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return errGELFTooLarge
+	}
+
+	id := make([]byte, 8)
+	_, err := rand.Read(id)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < count; i++ {
+		data := msg[i*dataSize : min((i+1)*dataSize, len(msg))]
+
+		chunk := append([]byte{0x1e, 0x0f}, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data...)
+		_, err = s.conn.Write(chunk)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GELFSink) connect(ctx context.Context) error {
+	if s.conn != nil {
+		return nil
+	}
+
+	network := s.Network
+	if network == "" {
+		network = "udp"
+	}
+
+	dial := s.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	conn, err := dial(ctx, network, s.Address)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	if !isDatagram(network) {
+		s.writer = bufio.NewWriter(conn)
+	}
+	return nil
+}
+
+// Close closes the connection to Graylog
+func (s *GELFSink) Close() error {
+	s.close()
+	return nil
+}
+
+func (s *GELFSink) close() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		s.writer = nil
+	}
+}
@@ -0,0 +1,116 @@
+package tailreadernet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGELFSinkOverUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	s := &GELFSink{Address: pc.LocalAddr().String(), Host: "web1", Level: Warning}
+	defer s.Close()
+
+	ts := time.Unix(1700000000, 250e6)
+	err = s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte("Hello"), Time: ts})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush(context.Background()))
+
+	buf := make([]byte, 2048)
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+
+	var msg map[string]any
+	assert.NoError(t, json.Unmarshal(buf[:n], &msg))
+	assert.Equal(t, map[string]any{
+		"version":       "1.1",
+		"host":          "web1",
+		"short_message": "Hello",
+		"timestamp":     1700000000.25,
+		"level":         float64(4),
+		"_path":         "/var/log/app.log",
+	}, msg)
+}
+
+func TestGELFSinkChunked(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	s := &GELFSink{Address: pc.LocalAddr().String(), Host: "web1", Compress: true, ChunkSize: 100}
+	defer s.Close()
+
+	// random-ish data that doesn't compress into a single chunk
+	var data strings.Builder
+	for i := 0; i < 200; i++ {
+		data.WriteString(time.Duration(i * 7919).String())
+	}
+
+	err = s.Write(context.Background(), tailreader.Record{Data: []byte(data.String()), Time: time.Now()})
+	assert.NoError(t, err)
+
+	buf := make([]byte, 2048)
+	var compressed []byte
+	count := 0
+	for i := 0; count == 0 || i < count; i++ {
+		n, _, err := pc.ReadFrom(buf)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, n, 100)
+		assert.Equal(t, []byte{0x1e, 0x0f}, buf[:2])
+		assert.Equal(t, byte(i), buf[10])
+		count = int(buf[11])
+		compressed = append(compressed, buf[12:n]...)
+	}
+	assert.Greater(t, count, 1)
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.NoError(t, err)
+	decompressed, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+
+	var msg map[string]any
+	assert.NoError(t, json.Unmarshal(decompressed, &msg))
+	assert.Equal(t, data.String(), msg["short_message"])
+}
+
+func TestGELFSinkOverTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	s := &GELFSink{Network: "tcp", Address: ln.Addr().String(), Host: "web1"}
+	defer s.Close()
+
+	for _, data := range []string{"Hello", "World"} {
+		err = s.Write(context.Background(), tailreader.Record{Data: []byte(data), Time: time.Now()})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, s.Flush(context.Background()))
+
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for _, expected := range []string{"Hello", "World"} {
+		msg, err := r.ReadBytes(0)
+		assert.NoError(t, err)
+
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(msg[:len(msg)-1], &m))
+		assert.Equal(t, expected, m["short_message"])
+	}
+}
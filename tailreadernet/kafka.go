@@ -0,0 +1,94 @@
+package tailreadernet
+
+import (
+	"context"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// DefaultKafkaBatchSize is the maximum number of messages produced at once if none is set
+const DefaultKafkaBatchSize = 500
+
+// KafkaMessage is a message produced to Kafka
+type KafkaMessage struct {
+	Topic string
+	Key   []byte // nil for no key
+	Value []byte
+	Time  time.Time
+
+	// Headers are the labels of the record (see tailreader.WithLabels)
+	Headers map[string]string
+}
+
+// KafkaProducer produces messages to Kafka
+//
+// It's implemented by adapters of Kafka clients (e.g. around kafka-go's Writer
+// or franz-go's Client), so no particular client is imposed. Produce returns
+// once all messages have been acknowledged.
+type KafkaProducer interface {
+	Produce(ctx context.Context, messages []KafkaMessage) error
+}
+
+// KafkaSink is a tailreader.Sink producing records to a Kafka topic
+//
+// Records are produced in batches of up to BatchSize messages, and whatever
+// is left when the Manager flushes the sink.
+type KafkaSink struct {
+	Producer KafkaProducer
+	Topic    string
+
+	// Key returns the key of the message of a record; nil keys messages by the
+	// record's path, so the records of a file stay in order within a partition
+	Key func(rec tailreader.Record) []byte
+
+	// BatchSize is the maximum number of messages produced at once; 0 uses DefaultKafkaBatchSize
+	BatchSize int
+
+	batch []KafkaMessage
+}
+
+func (s *KafkaSink) Write(ctx context.Context, rec tailreader.Record) error {
+	key := []byte(rec.Path)
+	if s.Key != nil {
+		key = s.Key(rec)
+	}
+
+	s.batch = append(s.batch, KafkaMessage{
+		Topic: s.Topic,
+		Key:   key,
+		Value: rec.Data,
+		Time:  rec.EventTime(),
+
+		Headers: rec.Labels,
+	})
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultKafkaBatchSize
+	}
+	if len(s.batch) >= batchSize {
+		err := s.Flush(ctx)
+		if err != nil {
+			// keep rec out of the batch, the retry adds it again
+			s.batch = s.batch[:len(s.batch)-1]
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *KafkaSink) Flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	err := s.Producer.Produce(ctx, s.batch)
+	if err != nil {
+		// keep the batch to retry with the next flush
+		return err
+	}
+
+	s.batch = nil
+	return nil
+}
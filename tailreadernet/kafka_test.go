@@ -0,0 +1,86 @@
+package tailreadernet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProducer is a KafkaProducer recording the batches produced
+type fakeProducer struct {
+	batches [][]KafkaMessage
+	err     error
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, messages []KafkaMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.batches = append(p.batches, messages)
+	return nil
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := &fakeProducer{}
+	s := &KafkaSink{Producer: producer, Topic: "logs", BatchSize: 2}
+
+	now := time.Now()
+	for _, data := range []string{"a", "b", "c"} {
+		err := s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte(data), Time: now})
+		assert.NoError(t, err)
+	}
+
+	// a full batch is produced right away
+	assert.Len(t, producer.batches, 1)
+	assert.Equal(t, []KafkaMessage{
+		{Topic: "logs", Key: []byte("/var/log/app.log"), Value: []byte("a"), Time: now},
+		{Topic: "logs", Key: []byte("/var/log/app.log"), Value: []byte("b"), Time: now},
+	}, producer.batches[0])
+
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, producer.batches, 2)
+	assert.Equal(t, "c", string(producer.batches[1][0].Value))
+
+	// nothing left to produce
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, producer.batches, 2)
+}
+
+func TestKafkaSinkWithKey(t *testing.T) {
+	producer := &fakeProducer{err: errors.New("unavailable")}
+	s := &KafkaSink{Producer: producer, Topic: "logs", Key: func(rec tailreader.Record) []byte {
+		return nil
+	}}
+
+	err := s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte("a")})
+	assert.NoError(t, err)
+	assert.Error(t, s.Flush(context.Background()))
+
+	// the batch is retried with the next flush
+	producer.err = nil
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Equal(t, [][]KafkaMessage{{{Topic: "logs", Value: []byte("a")}}}, producer.batches)
+}
+
+func TestKafkaSinkWriteFailed(t *testing.T) {
+	producer := &fakeProducer{err: errors.New("unavailable")}
+	s := &KafkaSink{Producer: producer, Topic: "logs", BatchSize: 2}
+
+	rec := func(data string) tailreader.Record {
+		return tailreader.Record{Path: "/var/log/app.log", Data: []byte(data)}
+	}
+	assert.NoError(t, s.Write(context.Background(), rec("a")))
+	assert.Error(t, s.Write(context.Background(), rec("b")))
+
+	// the failed record isn't kept, so writing it again doesn't duplicate it
+	producer.err = nil
+	assert.NoError(t, s.Write(context.Background(), rec("b")))
+	assert.Equal(t, [][]KafkaMessage{{
+		{Topic: "logs", Key: []byte("/var/log/app.log"), Value: []byte("a")},
+		{Topic: "logs", Key: []byte("/var/log/app.log"), Value: []byte("b")},
+	}}, producer.batches)
+}
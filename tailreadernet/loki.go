@@ -0,0 +1,134 @@
+package tailreadernet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// DefaultLokiBatchSize is the maximum number of records pushed at once if none is set
+const DefaultLokiBatchSize = 1000
+
+// LokiSink is a tailreader.Sink pushing records to Grafana Loki's push API
+//
+// Every record is labeled with its path as "filename" (like Promtail does) in
+// addition to the static Labels and the record's own labels (see
+// tailreader.WithLabels), which take precedence. Records are pushed in batches of up to
+// BatchSize, and whatever is left when the Manager flushes the sink.
+type LokiSink struct {
+	URL      string            // URL of the push API, e.g. http://loki:3100/loki/api/v1/push
+	Labels   map[string]string // static labels of all records, e.g. job or environment
+	TenantID string            // sent as X-Scope-OrgID if set
+
+	// BatchSize is the maximum number of records pushed at once; 0 uses DefaultLokiBatchSize
+	BatchSize int
+
+	// Client is used to push; nil uses http.DefaultClient
+	Client *http.Client
+
+	streams map[string]*lokiStream // streams of the current batch by labels
+	size    int                    // number of records in the current batch
+}
+
+// lokiStream is a stream of the push API's JSON format
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"` // timestamp in nanoseconds and line
+}
+
+func (s *LokiSink) Write(ctx context.Context, rec tailreader.Record) error {
+	if s.streams == nil {
+		s.streams = make(map[string]*lokiStream)
+	}
+
+	labels := maps.Clone(s.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	maps.Copy(labels, rec.Labels)
+	labels["filename"] = rec.Path
+
+	// maps are printed with sorted keys
+	key := fmt.Sprint(labels)
+	stream, ok := s.streams[key]
+	if !ok {
+		stream = &lokiStream{Stream: labels}
+		s.streams[key] = stream
+	}
+
+	stream.Values = append(stream.Values, [2]string{strconv.FormatInt(rec.EventTime().UnixNano(), 10), string(rec.Data)})
+	s.size++
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultLokiBatchSize
+	}
+	if s.size >= batchSize {
+		err := s.Flush(ctx)
+		if err != nil {
+			// remove the value from its stream, and the stream if rec started it
+			stream.Values = stream.Values[:len(stream.Values)-1]
+			if !ok {
+				delete(s.streams, key)
+			}
+			s.size--
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *LokiSink) Flush(ctx context.Context) error {
+	if s.size == 0 {
+		return nil
+	}
+
+	var push struct {
+		Streams []*lokiStream `json:"streams"`
+	}
+	for _, stream := range s.streams {
+		push.Streams = append(push.Streams, stream)
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.TenantID)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// keep the batch to retry with the next flush
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("loki: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	s.streams = nil
+	s.size = 0
+	return nil
+}
@@ -0,0 +1,89 @@
+package tailreadernet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLokiSink(t *testing.T) {
+	var pushes []map[string]any
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+
+		var push map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+		pushes = append(pushes, push)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := &LokiSink{URL: server.URL + "/loki/api/v1/push", Labels: map[string]string{"job": "app"}, TenantID: "tenant"}
+
+	ts := time.Unix(1700000000, 5)
+	err := s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte("Hello"), Time: ts, Labels: map[string]string{"env": "prod"}})
+	assert.NoError(t, err)
+	err = s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte("World"), Time: ts, Labels: map[string]string{"env": "prod"}})
+	assert.NoError(t, err)
+	assert.Empty(t, pushes)
+
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Equal(t, []map[string]any{{"streams": []any{map[string]any{
+		"stream": map[string]any{"job": "app", "env": "prod", "filename": "/var/log/app.log"},
+		"values": []any{[]any{"1700000000000000005", "Hello"}, []any{"1700000000000000005", "World"}},
+	}}}}, pushes)
+
+	// a failed push is retried with the next flush
+	status = http.StatusTooManyRequests
+	err = s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Data: []byte("again"), Time: ts})
+	assert.NoError(t, err)
+	assert.ErrorContains(t, s.Flush(context.Background()), "429")
+
+	status = http.StatusNoContent
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, pushes, 3)
+	assert.Equal(t, pushes[1], pushes[2])
+
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, pushes, 3)
+}
+
+func TestLokiSinkWriteFailed(t *testing.T) {
+	var pushes []map[string]any
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var push map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+		if status/100 == 2 {
+			pushes = append(pushes, push)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := &LokiSink{URL: server.URL, BatchSize: 2}
+
+	ts := time.Unix(1700000000, 0)
+	assert.NoError(t, s.Write(context.Background(), tailreader.Record{Path: "a.log", Data: []byte("a"), Time: ts}))
+	assert.Error(t, s.Write(context.Background(), tailreader.Record{Path: "b.log", Data: []byte("b"), Time: ts}))
+
+	// the failed record isn't kept, so writing it again doesn't duplicate it
+	status = http.StatusNoContent
+	assert.NoError(t, s.Write(context.Background(), tailreader.Record{Path: "b.log", Data: []byte("b"), Time: ts}))
+	assert.Len(t, pushes, 1)
+
+	var values []any
+	for _, stream := range pushes[0]["streams"].([]any) {
+		values = append(values, stream.(map[string]any)["values"].([]any)...)
+	}
+	assert.ElementsMatch(t, []any{[]any{"1700000000000000000", "a"}, []any{"1700000000000000000", "b"}}, values)
+}
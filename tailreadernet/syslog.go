@@ -0,0 +1,158 @@
+package tailreadernet
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// Facility is a syslog facility as defined in RFC 5424
+type Facility int
+
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	LPR
+	News
+	UUCP
+	Cron
+	AuthPriv
+	FTP
+	Local0 Facility = iota + 4
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// Severity is a syslog severity as defined in RFC 5424
+type Severity int
+
+const (
+	Emergency Severity = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+// severityOf returns the syslog severity of level, or fallback for LevelNone
+func severityOf(level tailreader.Level, fallback Severity) Severity {
+	switch level {
+	case tailreader.LevelTrace, tailreader.LevelDebug:
+		return Debug
+	case tailreader.LevelInfo:
+		return Informational
+	case tailreader.LevelWarn:
+		return Warning
+	case tailreader.LevelError:
+		return Error
+	case tailreader.LevelFatal:
+		return Critical
+	}
+	return fallback
+}
+
+// SyslogForwarder sends tailed lines as RFC 5424 syslog messages
+//
+// Over UDP, every message is sent as a separate datagram; over TCP and TLS,
+// messages are framed using octet counting (RFC 6587, RFC 5425).
+type SyslogForwarder struct {
+	Network   string // "udp", "tcp" or "tls"
+	Address   string
+	TLSConfig *tls.Config // used for "tls"
+
+	Facility Facility
+	Severity Severity
+	Hostname string // defaults to os.Hostname()
+	AppName  string // defaults to "-" (no value)
+
+	// MinBackoff and MaxBackoff limit the delay between reconnects; 0 uses the defaults
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Forward sends every line read from tr until the reader ends or ctx is done
+//
+// The reader is closed when Forward returns. It returns nil if the reader
+// ended with io.EOF.
+func (s *SyslogForwarder) Forward(ctx context.Context, tr *tailreader.TailingReader) error {
+	f := &Forwarder{
+		Network:    s.Network,
+		Address:    s.Address,
+		MinBackoff: s.MinBackoff,
+		MaxBackoff: s.MaxBackoff,
+	}
+
+	if s.Network == "tls" {
+		f.Network = "tcp"
+		f.Dial = (&tls.Dialer{Config: s.TLSConfig}).DialContext
+	}
+
+	hostname := s.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	var options []tailreader.RecordOption
+	if isDatagram(f.Network) {
+		options = append(options, tailreader.WithMaxRecordSize(maxDatagramSize-512))
+	}
+
+	rr := tailreader.NewRecordReader(tr, options...)
+	next := func() ([]byte, error) {
+		rec, err := rr.ReadRecord()
+		if err != nil {
+			return nil, err
+		}
+
+		msg := s.format(rec, hostname)
+		if !isDatagram(f.Network) {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		return msg, nil
+	}
+
+	return f.forward(ctx, tr, next)
+}
+
+// format returns rec as RFC 5424 message
+func (s *SyslogForwarder) format(rec tailreader.Record, hostname string) []byte {
+	pri := int(s.Facility)*8 + int(s.Severity)
+	header := fmt.Sprintf("<%d>1 %s %s %s - - - ",
+		pri,
+		rec.EventTime().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(hostname, 255),
+		headerField(s.AppName, 48),
+	)
+	return append([]byte(header), rec.Data...)
+}
+
+// headerField returns value as valid header field (printable ASCII, limited length, "-" if empty)
+func headerField(value string, maxLen int) string {
+	b := make([]byte, 0, len(value))
+	for i := 0; i < len(value) && len(b) < maxLen; i++ {
+		if value[i] > 32 && value[i] < 127 {
+			b = append(b, value[i])
+		}
+	}
+
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
@@ -0,0 +1,53 @@
+package tailreadernet
+
+import (
+	"context"
+	"net"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogForwarder_ForwardOverUDP(t *testing.T) {
+	file, _ := os.CreateTemp("", "test")
+	defer os.Remove(file.Name())
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	_, err = file.WriteString("Hello, World!\nHello again!\n")
+	assert.NoError(t, err)
+
+	tr, _ := tailreader.NewTailingReader(file.Name(), tailreader.WithIdleTimeout(200*time.Millisecond), tailreader.WithTimeoutsAsEOF(true))
+
+	s := &SyslogForwarder{
+		Network:  "udp",
+		Address:  pc.LocalAddr().String(),
+		Facility: Local3,
+		Severity: Warning,
+		Hostname: "host",
+		AppName:  "app",
+	}
+
+	go func() {
+		_ = s.Forward(context.Background(), tr)
+	}()
+
+	buf := make([]byte, 1024)
+	for _, line := range []string{"Hello, World!", "Hello again!"} {
+		n, _, err := pc.ReadFrom(buf)
+		assert.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^<156>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z host app - - - `+line+`$`), string(buf[:n]))
+	}
+}
+
+func TestSyslogForwarder_Format(t *testing.T) {
+	s := &SyslogForwarder{Facility: User, Severity: Notice}
+	msg := s.format(tailreader.Record{Data: []byte("Hello"), Time: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)}, "my host")
+	assert.Equal(t, "<13>1 2023-01-02T03:04:05.000000Z myhost - - - - Hello", string(msg))
+}
@@ -0,0 +1,186 @@
+package tailreadernet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+const (
+	// DefaultWebhookBatchSize is the maximum number of records posted at once if none is set
+	DefaultWebhookBatchSize = 1000
+
+	// DefaultWebhookRetries is the number of retries of a failed post if none is set
+	DefaultWebhookRetries = 5
+)
+
+// WebhookSink is a tailreader.Sink posting JSON batches of records to a URL
+//
+// Every batch is posted as a JSON array of objects with the fields path,
+// offset, generation, time (when the record was read), timestamp (see
+// tailreader.Record.EventTime), data, level and labels (if any). Records are posted in batches of up to BatchSize,
+// and whatever is left when the Manager flushes the sink. Failed posts
+// (connection errors, 429 and 5xx responses) are retried with exponential
+// backoff; if all retries fail, the batch is posted again by the next Flush.
+type WebhookSink struct {
+	URL     string
+	Headers map[string]string // additional request headers, e.g. Authorization
+
+	// Gzip enables compressing the request body
+	Gzip bool
+
+	// BatchSize is the maximum number of records posted at once; 0 uses DefaultWebhookBatchSize
+	BatchSize int
+
+	// Retries is the number of retries of a failed post; 0 uses DefaultWebhookRetries, a negative value disables retries
+	Retries int
+
+	// MinBackoff and MaxBackoff limit the delay between retries; 0 uses the defaults
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Client is used to post; nil uses http.DefaultClient
+	Client *http.Client
+
+	batch []webhookRecord
+}
+
+// webhookRecord is a record as posted by WebhookSink
+type webhookRecord struct {
+	Path       string            `json:"path"`
+	Offset     int64             `json:"offset"`
+	Generation int64             `json:"generation"`
+	Time       time.Time         `json:"time"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Data       string            `json:"data"`
+	Level      string            `json:"level,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// webhookError is a failed post, which is retried if temporary
+type webhookError struct {
+	status    string
+	body      []byte
+	temporary bool
+}
+
+func (e *webhookError) Error() string {
+	return fmt.Sprintf("webhook: %s: %s", e.status, e.body)
+}
+
+func (s *WebhookSink) Write(ctx context.Context, rec tailreader.Record) error {
+	s.batch = append(s.batch, webhookRecord{
+		Path:       rec.Path,
+		Offset:     rec.Offset,
+		Generation: rec.Generation,
+		Time:       rec.Time,
+		Timestamp:  rec.EventTime(),
+		Data:       string(rec.Data),
+		Level:      rec.Level.String(),
+		Labels:     rec.Labels,
+	})
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultWebhookBatchSize
+	}
+	if len(s.batch) >= batchSize {
+		err := s.Flush(ctx)
+		if err != nil {
+			// the batch is posted again without rec, which the retry writes back
+			s.batch = s.batch[:len(s.batch)-1]
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookSink) Flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(s.batch)
+	if err != nil {
+		return err
+	}
+
+	if s.Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(body)
+		_ = zw.Close()
+		body = buf.Bytes()
+	}
+
+	retries := s.Retries
+	if retries == 0 {
+		retries = DefaultWebhookRetries
+	}
+
+	backoff := time.Duration(0)
+	for attempt := 0; ; attempt++ {
+		err = s.post(ctx, body)
+		if err == nil {
+			s.batch = nil
+			return nil
+		}
+
+		if we, ok := err.(*webhookError); ok && !we.temporary || attempt >= retries {
+			// keep the batch to post it again with the next flush
+			return err
+		}
+
+		backoff = nextBackoff(backoff, s.MinBackoff, s.MaxBackoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// post sends body to the URL once
+func (s *WebhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range s.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &webhookError{
+			status:    resp.Status,
+			body:      bytes.TrimSpace(msg),
+			temporary: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5,
+		}
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
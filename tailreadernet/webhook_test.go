@@ -0,0 +1,81 @@
+package tailreadernet
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSink(t *testing.T) {
+	var batches [][]map[string]any
+	failures := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		assert.NoError(t, err)
+
+		var batch []map[string]any
+		assert.NoError(t, json.NewDecoder(zr).Decode(&batch))
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	s := &WebhookSink{
+		URL:        server.URL,
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+		Gzip:       true,
+		MinBackoff: time.Millisecond,
+	}
+
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := s.Write(context.Background(), tailreader.Record{Path: "/var/log/app.log", Offset: 6, Data: []byte("Hello"), Time: ts, Generation: 1, Labels: map[string]string{"service": "app"}})
+	assert.NoError(t, err)
+
+	// the post succeeds on the third attempt
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Equal(t, [][]map[string]any{{{
+		"path":       "/var/log/app.log",
+		"offset":     float64(6),
+		"generation": float64(1),
+		"time":       "2024-05-01T12:00:00Z",
+		"timestamp":  "2024-05-01T12:00:00Z",
+		"data":       "Hello",
+		"labels":     map[string]any{"service": "app"},
+	}}}, batches)
+
+	assert.NoError(t, s.Flush(context.Background()))
+	assert.Len(t, batches, 1)
+}
+
+func TestWebhookSinkPermanentError(t *testing.T) {
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		http.Error(w, "invalid batch", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	s := &WebhookSink{URL: server.URL, MinBackoff: time.Millisecond}
+	err := s.Write(context.Background(), tailreader.Record{Data: []byte("Hello")})
+	assert.NoError(t, err)
+
+	// client errors aren't retried
+	assert.ErrorContains(t, s.Flush(context.Background()), "invalid batch")
+	assert.Equal(t, 1, posts)
+	assert.Len(t, s.batch, 1)
+}
@@ -0,0 +1,53 @@
+package tailreaderobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GCSBucket is a Bucket backed by Google Cloud Storage
+type GCSBucket struct {
+	Bucket *storage.BucketHandle
+}
+
+func (b *GCSBucket) Stat(ctx context.Context, key string) (Object, error) {
+	attrs, err := b.Bucket.Object(key).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return Object{}, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+		return Object{}, err
+	}
+
+	return Object{
+		Size:       attrs.Size,
+		Generation: strconv.FormatInt(attrs.Generation, 10),
+		Updated:    attrs.Updated,
+	}, nil
+}
+
+func (b *GCSBucket) ReadRange(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	reader, err := b.Bucket.Object(key).NewRangeReader(ctx, offset, -1)
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusRequestedRangeNotSatisfiable || status.Code(err) == codes.OutOfRange {
+			return nil, io.EOF
+		}
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+		return nil, err
+	}
+
+	return reader, nil
+}
@@ -0,0 +1,172 @@
+// Package tailreaderobject tails objects in object storage (S3, GCS) that are
+// periodically rewritten or appended to.
+package tailreaderobject
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// Object describes a stored object
+type Object struct {
+	Size int64
+
+	// Generation changes whenever the object is written (e.g. an S3 ETag or a GCS generation)
+	Generation string
+
+	Updated time.Time
+}
+
+// Bucket is the part of an object storage API needed for tailing
+type Bucket interface {
+	// Stat returns the object's attributes; errors wrap fs.ErrNotExist if it doesn't exist
+	Stat(ctx context.Context, key string) (Object, error)
+
+	// ReadRange returns the object's data starting at offset; reading beyond
+	// the end of the object returns io.EOF
+	ReadRange(ctx context.Context, key string, offset int64) (io.ReadCloser, error)
+}
+
+// FS is a tailreader.FS for the objects of a bucket; names are object keys
+//
+// Objects can't be appended to in place, so a growing object is a series of
+// generations. The reader polls the object's attributes and fetches the range
+// following its offset from the current generation; a new generation of the
+// same size is read again from the start (see tailreader.VersionedFileInfo),
+// and a smaller one is handled like a truncated file.
+type FS struct {
+	Bucket Bucket
+
+	// RequestTimeout limits each request to the bucket; 0 means no limit
+	RequestTimeout time.Duration
+}
+
+// NewTailingReader creates a reader for the object key in bucket
+//
+// Without options, tailreader.DefaultOptions are used; the poll interval
+// (tailreader.WithPollInterval) sets how often the object's attributes are
+// fetched.
+func NewTailingReader(bucket Bucket, key string, options ...tailreader.Option) (*tailreader.TailingReader, error) {
+	if len(options) == 0 {
+		options = tailreader.DefaultOptions
+	}
+	options = append(options[:len(options):len(options)], tailreader.WithFS(&FS{Bucket: bucket}))
+
+	return tailreader.NewTailingReader(key, options...)
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	ctx, cancel := f.context()
+	defer cancel()
+
+	object, err := f.Bucket.Stat(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	return &objectInfo{name: name, object: object}, nil
+}
+
+func (f *FS) OpenFile(name string, flag int) (fs.File, error) {
+	return &objectFile{fs: f, name: name}, nil
+}
+
+func (f *FS) context() (context.Context, context.CancelFunc) {
+	if f.RequestTimeout > 0 {
+		return context.WithTimeout(context.Background(), f.RequestTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// objectFile reads an object from offset on
+//
+// The range reader is kept open across reads until it's consumed.
+type objectFile struct {
+	fs     *FS
+	name   string
+	offset int64
+	body   io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (f *objectFile) Stat() (fs.FileInfo, error) {
+	return f.fs.Stat(f.name)
+}
+
+func (f *objectFile) Read(p []byte) (int, error) {
+	if f.body == nil {
+		ctx, cancel := f.fs.context()
+		body, err := f.fs.Bucket.ReadRange(ctx, f.name, f.offset)
+		if err != nil {
+			cancel()
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+			return 0, &fs.PathError{Op: "read", Path: f.name, Err: err}
+		}
+		f.body, f.cancel = body, cancel
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	if err != nil {
+		f.closeBody()
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+	}
+
+	return n, err
+}
+
+func (f *objectFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	default:
+		return f.offset, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return f.offset, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset != f.offset {
+		f.closeBody()
+		f.offset = offset
+	}
+	return offset, nil
+}
+
+func (f *objectFile) Close() error {
+	f.closeBody()
+	return nil
+}
+
+func (f *objectFile) closeBody() {
+	if f.body != nil {
+		f.body.Close()
+		f.cancel()
+		f.body, f.cancel = nil, nil
+	}
+}
+
+// objectInfo describes an object
+type objectInfo struct {
+	name   string
+	object Object
+}
+
+func (i *objectInfo) Name() string       { return path.Base(i.name) }
+func (i *objectInfo) Size() int64        { return i.object.Size }
+func (i *objectInfo) Mode() fs.FileMode  { return 0444 }
+func (i *objectInfo) ModTime() time.Time { return i.object.Updated }
+func (i *objectInfo) IsDir() bool        { return false }
+func (i *objectInfo) Sys() any           { return i.object }
+func (i *objectInfo) Version() string    { return i.object.Generation }
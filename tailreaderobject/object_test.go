@@ -0,0 +1,98 @@
+package tailreaderobject
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// memBucket is a Bucket holding objects in memory
+type memBucket struct {
+	mu         sync.Mutex
+	objects    map[string][]byte
+	generation int
+}
+
+func (b *memBucket) put(key string, data string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.objects == nil {
+		b.objects = map[string][]byte{}
+	}
+	b.objects[key] = []byte(data)
+	b.generation++
+}
+
+func (b *memBucket) Stat(ctx context.Context, key string) (Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[key]
+	if !ok {
+		return Object{}, fs.ErrNotExist
+	}
+	return Object{Size: int64(len(data)), Generation: fmt.Sprint(b.generation)}, nil
+}
+
+func (b *memBucket) ReadRange(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	if offset >= int64(len(data)) {
+		return nil, io.EOF
+	}
+	return io.NopCloser(bytes.NewReader(data[offset:])), nil
+}
+
+func TestNewTailingReader(t *testing.T) {
+	bucket := &memBucket{}
+	bucket.put("app.log", "Hello,")
+
+	tr, err := NewTailingReader(bucket, "app.log",
+		tailreader.WithPollInterval(10*time.Millisecond),
+		tailreader.WithIdleTimeout(200*time.Millisecond),
+		tailreader.WithTimeoutsAsEOF(true),
+	)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 64)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello,", string(buf[:n]))
+
+	// appended by writing a new generation
+	bucket.put("app.log", "Hello, World!")
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " World!", string(buf[:n]))
+
+	// rewritten with the same size
+	bucket.put("app.log", "Goodbye World")
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Goodbye World", string(buf[:n]))
+
+	_, err = tr.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNewTailingReader_NotFound(t *testing.T) {
+	tr, err := NewTailingReader(&memBucket{}, "app.log", tailreader.WithWaitForFile(false, 0))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = tr.Read(make([]byte, 64))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
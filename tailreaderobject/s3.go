@@ -0,0 +1,64 @@
+package tailreaderobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Bucket is a Bucket backed by Amazon S3 (or any S3 compatible storage)
+//
+// The object's ETag is used as its generation.
+type S3Bucket struct {
+	Client *s3.Client
+	Name   string
+}
+
+func (b *S3Bucket) Stat(ctx context.Context, key string) (Object, error) {
+	out, err := b.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.Name),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return Object{}, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+		return Object{}, err
+	}
+
+	return Object{
+		Size:       aws.ToInt64(out.ContentLength),
+		Generation: aws.ToString(out.ETag),
+		Updated:    aws.ToTime(out.LastModified),
+	}, nil
+}
+
+func (b *S3Bucket) ReadRange(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	out, err := b.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.Name),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-", offset)),
+	})
+	if err != nil {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusRequestedRangeNotSatisfiable {
+			return nil, io.EOF
+		}
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+		return nil, err
+	}
+
+	return out.Body, nil
+}
@@ -0,0 +1,56 @@
+package tailreaderobject
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3Bucket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/logs/app.log" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"1"`)
+		http.ServeContent(w, req, "app.log", time.Unix(0, 0), bytes.NewReader([]byte("Hello, World!")))
+	}))
+	defer server.Close()
+
+	bucket := &S3Bucket{
+		Client: s3.New(s3.Options{
+			BaseEndpoint: aws.String(server.URL),
+			Region:       "us-east-1",
+			UsePathStyle: true,
+			Credentials:  aws.AnonymousCredentials{},
+		}),
+		Name: "logs",
+	}
+	ctx := context.Background()
+
+	object, err := bucket.Stat(ctx, "app.log")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13), object.Size)
+	assert.Equal(t, `"1"`, object.Generation)
+
+	_, err = bucket.Stat(ctx, "missing.log")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	body, err := bucket.ReadRange(ctx, "app.log", 7)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "World!", string(data))
+
+	_, err = bucket.ReadRange(ctx, "app.log", 13)
+	assert.Equal(t, io.EOF, err)
+}
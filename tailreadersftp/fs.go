@@ -0,0 +1,49 @@
+// Package tailreadersftp tails files on remote hosts over SFTP.
+package tailreadersftp
+
+import (
+	"io/fs"
+
+	"github.com/pkg/sftp"
+
+	"github.com/maurice2k/tailreader"
+)
+
+// FS is a tailreader.FS accessing files through an SFTP client
+//
+// The reader detects changes by polling the remote file's attributes (see
+// tailreader.WithPollInterval) and fetches new data with ranged reads from its
+// current offset, so nothing but an SFTP server is needed on the remote host.
+type FS struct {
+	Client *sftp.Client
+}
+
+// NewFS returns an FS using client
+func NewFS(client *sftp.Client) *FS {
+	return &FS{Client: client}
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	return f.Client.Stat(name)
+}
+
+func (f *FS) OpenFile(name string, flag int) (fs.File, error) {
+	file, err := f.Client.OpenFile(name, flag)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// NewTailingReader creates a reader for the file at path on the SFTP server
+//
+// All reader options apply as for local files; without options,
+// tailreader.DefaultOptions are used.
+func NewTailingReader(client *sftp.Client, path string, options ...tailreader.Option) (*tailreader.TailingReader, error) {
+	if len(options) == 0 {
+		options = tailreader.DefaultOptions
+	}
+	options = append(options[:len(options):len(options)], tailreader.WithFS(NewFS(client)))
+
+	return tailreader.NewTailingReader(path, options...)
+}
@@ -0,0 +1,88 @@
+package tailreadersftp
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maurice2k/tailreader"
+)
+
+func newTestClient(t *testing.T) *sftp.Client {
+	serverConn, clientConn := pipeConn()
+	server := sftp.NewRequestServer(serverConn, sftp.InMemHandler())
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	assert.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// pipeConn returns both ends of an in-memory connection
+func pipeConn() (io.ReadWriteCloser, io.ReadWriteCloser) {
+	serverRead, clientWrite := io.Pipe()
+	clientRead, serverWrite := io.Pipe()
+	return &pipe{serverRead, serverWrite}, &pipe{clientRead, clientWrite}
+}
+
+type pipe struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (p *pipe) Close() error {
+	p.PipeReader.Close()
+	return p.PipeWriter.Close()
+}
+
+func TestNewTailingReader(t *testing.T) {
+	client := newTestClient(t)
+
+	file, err := client.OpenFile("/test.log", os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("Hello,"))
+	assert.NoError(t, err)
+
+	tr, err := NewTailingReader(client, "/test.log",
+		tailreader.WithPollInterval(10*time.Millisecond),
+		tailreader.WithIdleTimeout(200*time.Millisecond),
+		tailreader.WithTimeoutsAsEOF(true),
+	)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	buf := make([]byte, 64)
+	n, err := tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello,", string(buf[:n]))
+
+	_, err = file.Write([]byte(" World!"))
+	assert.NoError(t, err)
+
+	n, err = tr.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, " World!", string(buf[:n]))
+
+	_, err = tr.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNewTailingReader_NotFound(t *testing.T) {
+	client := newTestClient(t)
+
+	tr, err := NewTailingReader(client, "/missing.log", tailreader.WithWaitForFile(false, 0))
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	_, err = tr.Read(make([]byte, 64))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,20 @@
+module github.com/maurice2k/tailreader/tailreadersftp
+
+go 1.25.0
+
+require (
+	github.com/maurice2k/tailreader v0.0.0-20261015134957-ceb48d796bb3
+	github.com/pkg/sftp v1.13.10
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/kr/fs v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	golang.org/x/text v0.38.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
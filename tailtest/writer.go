@@ -0,0 +1,239 @@
+// Package tailtest simulates processes writing to a tailed file.
+//
+// A Writer appends to, truncates, rotates and deletes a file the way loggers
+// and log rotation tools do, either directly or following a script of steps
+// with delays, so tailing behavior can be tested deterministically.
+package tailtest
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer writes to the file at Path
+//
+// Its methods are safe for concurrent use. The file is created (or opened for
+// appending) by NewWriter and after Rotate or Recreate.
+type Writer struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File // nil after Delete
+}
+
+// NewWriter creates a writer for path, appending to the file if it exists
+func NewWriter(path string) (*Writer, error) {
+	w := &Writer{Path: path}
+
+	err := w.open(os.O_CREATE)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open(flag int) error {
+	file, err := os.OpenFile(w.Path, flag|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	return nil
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// Append appends data to the file
+func (w *Writer) Append(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrNotExist
+	}
+	_, err := w.file.Write(data)
+	return err
+}
+
+// AppendString appends s to the file
+func (w *Writer) AppendString(s string) error {
+	return w.Append([]byte(s))
+}
+
+// Truncate changes the size of the file; writing continues at its new end
+func (w *Writer) Truncate(size int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrNotExist
+	}
+	return w.file.Truncate(size)
+}
+
+// Rotate renames the file to Path+suffix and creates a new one, like logrotate's default mode
+func (w *Writer) Rotate(suffix string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.closeFile()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(w.Path, w.Path+suffix)
+	if err != nil {
+		return err
+	}
+	return w.open(os.O_CREATE | os.O_EXCL)
+}
+
+// CopyTruncate copies the file to Path+suffix and truncates it, like logrotate's copytruncate
+func (w *Writer) CopyTruncate(suffix string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrNotExist
+	}
+
+	src, err := os.Open(w.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(w.Path + suffix)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.file.Truncate(0)
+}
+
+// Delete closes and deletes the file
+func (w *Writer) Delete() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.closeFile()
+	if err != nil {
+		return err
+	}
+	return os.Remove(w.Path)
+}
+
+// Recreate deletes the file (if it exists) and creates a new, empty one
+func (w *Writer) Recreate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.closeFile()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(w.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return w.open(os.O_CREATE | os.O_EXCL)
+}
+
+// Close closes the file without deleting it
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeFile()
+}
+
+// Step is an operation of a script, performed after waiting for Delay
+type Step struct {
+	Delay time.Duration
+	Do    func(w *Writer) error
+}
+
+// After returns the step delayed by d
+func (s Step) After(d time.Duration) Step {
+	s.Delay = d
+	return s
+}
+
+// Append returns a step appending s to the file, see Writer.AppendString
+func Append(s string) Step {
+	return Step{Do: func(w *Writer) error { return w.AppendString(s) }}
+}
+
+// Truncate returns a step truncating the file to size, see Writer.Truncate
+func Truncate(size int64) Step {
+	return Step{Do: func(w *Writer) error { return w.Truncate(size) }}
+}
+
+// Rotate returns a step rotating the file, see Writer.Rotate
+func Rotate(suffix string) Step {
+	return Step{Do: func(w *Writer) error { return w.Rotate(suffix) }}
+}
+
+// CopyTruncate returns a step copying and truncating the file, see Writer.CopyTruncate
+func CopyTruncate(suffix string) Step {
+	return Step{Do: func(w *Writer) error { return w.CopyTruncate(suffix) }}
+}
+
+// Delete returns a step deleting the file, see Writer.Delete
+func Delete() Step {
+	return Step{Do: (*Writer).Delete}
+}
+
+// Recreate returns a step recreating the file, see Writer.Recreate
+func Recreate() Step {
+	return Step{Do: (*Writer).Recreate}
+}
+
+// Run performs the steps in order, stopping at the first error or when ctx is done
+func (w *Writer) Run(ctx context.Context, steps ...Step) error {
+	for _, step := range steps {
+		if step.Delay > 0 {
+			timer := time.NewTimer(step.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+
+		err := step.Do(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start runs the steps in the background; the returned channel receives the result of Run
+func (w *Writer) Start(ctx context.Context, steps ...Step) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, steps...)
+	}()
+	return done
+}
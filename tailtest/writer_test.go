@@ -0,0 +1,73 @@
+package tailtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	w, err := NewWriter(path)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	assert.NoError(t, w.AppendString("Hello, World!"))
+	assert.NoError(t, w.Truncate(5))
+	assert.NoError(t, w.AppendString("!"))
+	assert.Equal(t, "Hello!", readFile(t, path))
+
+	assert.NoError(t, w.Rotate(".1"))
+	assert.NoError(t, w.AppendString("rotated"))
+	assert.Equal(t, "Hello!", readFile(t, path+".1"))
+	assert.Equal(t, "rotated", readFile(t, path))
+
+	assert.NoError(t, w.CopyTruncate(".2"))
+	assert.NoError(t, w.AppendString("copied"))
+	assert.Equal(t, "rotated", readFile(t, path+".2"))
+	assert.Equal(t, "copied", readFile(t, path))
+
+	assert.NoError(t, w.Delete())
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorIs(t, w.AppendString("lost"), os.ErrNotExist)
+
+	assert.NoError(t, w.Recreate())
+	assert.NoError(t, w.AppendString("recreated"))
+	assert.Equal(t, "recreated", readFile(t, path))
+}
+
+func TestWriter_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	w, err := NewWriter(path)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	start := time.Now()
+	err = w.Run(context.Background(),
+		Append("Hello"),
+		Rotate(".1").After(50*time.Millisecond),
+		Append("World"),
+	)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, "Hello", readFile(t, path+".1"))
+	assert.Equal(t, "World", readFile(t, path))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := w.Start(ctx, Append("never").After(time.Hour))
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
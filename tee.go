@@ -0,0 +1,61 @@
+package tailreader
+
+import (
+	"bytes"
+	"io"
+)
+
+// TeeReader writes everything read from a TailingReader to a secondary writer
+//
+// The writer (e.g. an archive file or a hash) sees exactly the bytes delivered
+// to the consumer, across rotations and truncations. If the writer fails or
+// accepts only part of the data, Read returns just the written part along with
+// the error; the rest is delivered (and written) again by the next Read.
+type TeeReader struct {
+	tr *TailingReader
+	w  io.Writer
+}
+
+// NewTeeReader returns a TeeReader writing to w what it reads from tr
+func NewTeeReader(tr *TailingReader, w io.Writer) *TeeReader {
+	return &TeeReader{tr: tr, w: w}
+}
+
+func (t *TeeReader) Read(p []byte) (int, error) {
+	n, err := t.tr.Read(p)
+	if n > 0 {
+		written, writeErr := t.w.Write(p[:n])
+		if writeErr == nil && written < n {
+			writeErr = io.ErrShortWrite
+		}
+		if writeErr != nil {
+			t.tr.unread(p[written:n])
+			return written, writeErr
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying TailingReader
+func (t *TeeReader) Close() error {
+	return t.tr.Close()
+}
+
+// unread returns data to the reader to be delivered again by the next Read
+//
+// The data no longer counts as delivered. As it can't be removed from the
+// checksum, it isn't hashed again when it's delivered again.
+func (r *TailingReader) unread(data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unreadData = append(append([]byte(nil), data...), r.unreadData...)
+	r.delivered -= int64(len(data))
+	r.records -= int64(bytes.Count(data, []byte{'\n'}))
+	if r.hash != nil {
+		r.rehash += len(data)
+	}
+	if r.rateLimit() > 0 {
+		r.rateTokens += float64(len(data))
+	}
+}
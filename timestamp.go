@@ -0,0 +1,88 @@
+package tailreader
+
+import (
+	"regexp"
+	"time"
+)
+
+// TimestampExtractor extracts the timestamp of a record, returning false if it has none
+//
+// A TimeParser is a TimestampExtractor, so any function can be used.
+type TimestampExtractor interface {
+	ExtractTimestamp(data []byte) (time.Time, bool)
+}
+
+func (p TimeParser) ExtractTimestamp(data []byte) (time.Time, bool) {
+	return p(data)
+}
+
+// LayoutExtractor extracts timestamps found by a regular expression in a time layout
+type LayoutExtractor struct {
+	// Regexp finds the timestamp, which is its first submatch or (without submatches) the whole match
+	Regexp *regexp.Regexp
+
+	// Layout is the format of the timestamp as for time.Parse, e.g. time.RFC3339
+	Layout string
+
+	// Location is the time zone of timestamps without one; nil uses UTC
+	Location *time.Location
+}
+
+// NewLayoutExtractor creates a LayoutExtractor for timestamps matching pattern in layout
+func NewLayoutExtractor(pattern string, layout string) (*LayoutExtractor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &LayoutExtractor{Regexp: re, Layout: layout}, nil
+}
+
+func (e *LayoutExtractor) ExtractTimestamp(data []byte) (time.Time, bool) {
+	match := e.Regexp.FindSubmatch(data)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	value := match[0]
+	if len(match) > 1 {
+		value = match[1]
+	}
+
+	loc := e.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	ts, err := time.ParseInLocation(e.Layout, string(value), loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// WithTimestampExtractor sets the extractor of the timestamps of records (see Record.Timestamp)
+//
+// Records without a timestamp get the time they were read.
+func WithTimestampExtractor(extractor TimestampExtractor) RecordOption {
+	return func(rr *RecordReader) {
+		rr.extractor = extractor
+	}
+}
+
+// timestamp returns the timestamp of data, or received if it has none
+func (rr *RecordReader) timestamp(data []byte, received time.Time) time.Time {
+	if rr.extractor != nil {
+		if ts, ok := rr.extractor.ExtractTimestamp(data); ok {
+			return ts
+		}
+	}
+	return received
+}
+
+// EventTime returns the timestamp of the record, or the time it was read if it has none
+func (rec Record) EventTime() time.Time {
+	if rec.Timestamp.IsZero() {
+		return rec.Time
+	}
+	return rec.Timestamp
+}
@@ -0,0 +1,14 @@
+//go:build darwin
+
+package tailreader
+
+import "golang.org/x/text/unicode/norm"
+
+// normalizeUnicode returns path in Unicode normalization form C
+//
+// macOS file systems don't distinguish between normalization forms (HFS+ even
+// stores names decomposed), so event names may use a different form than the
+// configured path.
+func normalizeUnicode(path string) string {
+	return norm.NFC.String(path)
+}
@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package tailreader
+
+// normalizeUnicode returns path unchanged; only macOS file systems ignore Unicode normalization
+func normalizeUnicode(path string) string {
+	return path
+}
@@ -0,0 +1,457 @@
+package tailreader
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// walHeaderSize is the size of the header of every entry: payload length, CRC-32 of
+	// the length and CRC-32 of the payload
+	walHeaderSize = 12
+)
+
+var (
+	errWALFull     = fmt.Errorf("wal: record exceeds the maximum size")
+	errWALInvalid  = fmt.Errorf("wal: invalid entry")
+	errWALChecksum = fmt.Errorf("wal: checksum mismatch")
+	errWALHeader   = fmt.Errorf("wal: header checksum mismatch")
+	errWALCorrupt  = fmt.Errorf("wal: corrupt entry")
+)
+
+// wal is a write-ahead log of records waiting to be delivered to a sink
+//
+// Records are appended to a single file, and a cursor file keeps the offset
+// of the first record not yet delivered. Once everything is delivered, the
+// log is truncated. An entry torn by a crash at the end of the log (one
+// reaching its end) is dropped when reading; a corrupt entry before the end is
+// an error.
+type wal struct {
+	mu         sync.Mutex
+	file       *os.File
+	cursorPath string
+	size       int64 // end of the log
+	cursor     int64 // offset of the first record not yet delivered
+	maxSize    int64 // size at which appending waits, 0 if unlimited
+
+	appended chan struct{} // signals the reader that records were appended
+	freed    chan struct{} // signals writers that the log was truncated
+}
+
+// openWAL opens (or creates) the log in dir, continuing at its saved cursor
+func openWAL(dir string, maxSize int64) (*wal, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, "wal"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	w := &wal{
+		file:       file,
+		cursorPath: filepath.Join(dir, "cursor"),
+		size:       info.Size(),
+		maxSize:    maxSize,
+		appended:   make(chan struct{}, 1),
+		freed:      make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(w.cursorPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		_ = file.Close()
+		return nil, err
+	}
+	if len(data) == 8 {
+		w.cursor = int64(binary.BigEndian.Uint64(data))
+	}
+	if w.cursor > w.size {
+		w.cursor = 0
+	}
+
+	return w, nil
+}
+
+// append adds records to the log, waiting while it's full
+func (w *wal) append(ctx context.Context, records []Record) error {
+	var buf []byte
+	for _, rec := range records {
+		buf = appendWALEntry(buf, rec)
+	}
+
+	if w.maxSize > 0 && int64(len(buf)) > w.maxSize {
+		return errWALFull
+	}
+
+	for {
+		w.mu.Lock()
+		if w.maxSize == 0 || w.size+int64(len(buf)) <= w.maxSize {
+			break
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-w.freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer w.mu.Unlock()
+
+	_, err := w.file.WriteAt(buf, w.size)
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		return err
+	}
+
+	w.size += int64(len(buf))
+	signal(w.appended)
+	return nil
+}
+
+// read returns up to max records from the cursor and the offset following them
+func (w *wal) read(max int) ([]Record, int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := bufio.NewReader(io.NewSectionReader(w.file, w.cursor, w.size-w.cursor))
+	offset := w.cursor
+
+	var records []Record
+	for len(records) < max {
+		rec, n, err := readWALEntry(r, w.size-offset)
+		if err == io.EOF {
+			break
+		} else if err != nil && err != io.ErrUnexpectedEOF && offset+int64(n) < w.size {
+			if len(records) > 0 {
+				// deliver the records before it first
+				break
+			}
+			return nil, 0, fmt.Errorf("%w at offset %d: %w", errWALCorrupt, offset, err)
+		} else if err != nil {
+			// an entry torn by a crash while appending; drop it
+			w.size = offset
+			err = w.file.Truncate(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			break
+		}
+
+		records = append(records, rec)
+		offset += int64(n)
+	}
+
+	return records, offset, nil
+}
+
+// commit marks the records up to offset as delivered
+func (w *wal) commit(offset int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if offset >= w.size {
+		// everything is delivered
+		err := w.file.Truncate(0)
+		if err == nil {
+			err = w.file.Sync()
+		}
+		if err != nil {
+			return err
+		}
+		w.size = 0
+		offset = 0
+		signal(w.freed)
+	}
+
+	w.cursor = offset
+	data := binary.BigEndian.AppendUint64(nil, uint64(offset))
+	tmp, err := os.Create(w.cursorPath + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	// sync the cursor before it replaces the old one, and the rename before returning
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), w.cursorPath)
+	if err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(w.cursorPath))
+}
+
+func (w *wal) Close() error {
+	return w.file.Close()
+}
+
+// UseWAL makes the Manager persist the records of every sink in a write-ahead log in dir before delivering them
+//
+// Tailing then doesn't depend on the sinks: records are appended to the log
+// of each sink, and delivered from it, so a failing sink is retried
+// indefinitely (see WithSinkRetries for the backoff) while its records
+// accumulate. Sinks with AtMostOnce delivery mark records as delivered in the
+// log before delivering them. Records not delivered when Run
+// returns are delivered by the next run. If maxSize is positive, reading
+// waits while a log has reached it. UseWAL must be called before Run.
+func (m *Manager) UseWAL(dir string, maxSize int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	if m.runCtx != nil {
+		return errManagerRunning
+	}
+
+	m.walDir = dir
+	m.walMaxSize = maxSize
+	return nil
+}
+
+// startDrain opens the write-ahead log of s and starts delivering from it, if logs are used
+func (m *Manager) startDrain(s *managedSink) error {
+	if m.walDir == "" {
+		return nil
+	}
+
+	w, err := openWAL(filepath.Join(m.walDir, url.PathEscape(s.name)), m.walMaxSize)
+	if err != nil {
+		return fmt.Errorf("sink %s: %w", s.name, err)
+	}
+	s.wal = w
+
+	ctx, cancel := m.runCtx, m.runCancel
+	m.drains.Add(1)
+	go func() {
+		defer m.drains.Done()
+
+		err := m.drain(ctx, s)
+		if err != nil && ctx.Err() == nil {
+			cancel(fmt.Errorf("sink %s: %w", s.name, err))
+		}
+	}()
+	return nil
+}
+
+// drain delivers the records of the write-ahead log of s until ctx is done or the log fails
+//
+// Failed deliveries are retried indefinitely, so errors are those of the log (or ctx.Err()).
+func (m *Manager) drain(ctx context.Context, s *managedSink) error {
+	for {
+		records, offset, err := s.wal.read(managerBatchSize)
+		if err == nil && len(records) == 0 {
+			select {
+			case <-s.wal.appended:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err == nil && s.mode == AtMostOnce {
+			err = s.wal.commit(offset)
+		}
+		if err == nil {
+			err = s.deliver(ctx, records, -1)
+		}
+		if err == nil && s.mode == AtLeastOnce {
+			err = s.wal.commit(offset)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// closeWALs closes the write-ahead logs of all sinks
+func (m *Manager) closeWALs() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, s := range m.sinks {
+		if s.wal != nil {
+			errs = append(errs, s.wal.Close())
+			s.wal = nil
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// appendWALEntry appends rec as entry: header, path, offset, time, timestamp, generation, level, labels and data
+func appendWALEntry(b []byte, rec Record) []byte {
+	var payload []byte
+	payload = appendWALString(payload, rec.Path)
+	payload = binary.AppendVarint(payload, rec.Offset)
+	payload = binary.AppendVarint(payload, walTime(rec.Time))
+	payload = binary.AppendVarint(payload, walTime(rec.Timestamp))
+	payload = binary.AppendVarint(payload, rec.Generation)
+	payload = binary.AppendVarint(payload, int64(rec.Level))
+	payload = binary.AppendUvarint(payload, uint64(len(rec.Labels)))
+	for key, value := range rec.Labels {
+		payload = appendWALString(payload, key)
+		payload = appendWALString(payload, value)
+	}
+	payload = append(payload, rec.Data...)
+
+	b = binary.BigEndian.AppendUint32(b, uint32(len(payload)))
+	b = binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[len(b)-4:]))
+	b = binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(payload))
+	return append(b, payload...)
+}
+
+// readWALEntry reads an entry of at most max bytes and returns its record and size
+//
+// The size is also returned for entries failing the checksum or not decoding.
+// io.ErrUnexpectedEOF is only returned for an entry reaching beyond max, i.e.
+// one torn while appending; the size of an entry with a corrupt header is unknown.
+func readWALEntry(r *bufio.Reader, max int64) (Record, int, error) {
+	header := make([]byte, walHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err == io.EOF || err == nil && n == 0 {
+		return Record{}, 0, io.EOF
+	} else if err != nil {
+		return Record{}, 0, err
+	}
+
+	if crc32.ChecksumIEEE(header[:4]) != binary.BigEndian.Uint32(header[4:]) {
+		return Record{}, 0, errWALHeader
+	}
+	size := binary.BigEndian.Uint32(header)
+	if int64(size) > max-walHeaderSize {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		// the payload is within max, so it's not torn
+		return Record{}, 0, fmt.Errorf("wal: %w", err)
+	}
+	entrySize := walHeaderSize + len(payload)
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[8:]) {
+		return Record{}, entrySize, errWALChecksum
+	}
+
+	rec, err := decodeWALPayload(payload)
+	if err != nil {
+		return Record{}, entrySize, err
+	}
+	return rec, entrySize, nil
+}
+
+// decodeWALPayload decodes the payload of an entry
+func decodeWALPayload(p []byte) (Record, error) {
+	var rec Record
+	var ok bool
+
+	rec.Path, p, ok = readWALString(p)
+	if !ok {
+		return Record{}, errWALInvalid
+	}
+
+	var values [5]int64
+	for i := range values {
+		var k int
+		values[i], k = binary.Varint(p)
+		if k <= 0 {
+			return Record{}, errWALInvalid
+		}
+		p = p[k:]
+	}
+	rec.Offset, rec.Time, rec.Timestamp, rec.Generation = values[0], fromWALTime(values[1]), fromWALTime(values[2]), values[3]
+	rec.Level = Level(values[4])
+
+	count, k := binary.Uvarint(p)
+	if k <= 0 || count > uint64(len(p)) {
+		return Record{}, errWALInvalid
+	}
+	p = p[k:]
+
+	if count > 0 {
+		rec.Labels = make(map[string]string, count)
+	}
+	for i := uint64(0); i < count; i++ {
+		var key, value string
+		key, p, ok = readWALString(p)
+		if ok {
+			value, p, ok = readWALString(p)
+		}
+		if !ok {
+			return Record{}, errWALInvalid
+		}
+		rec.Labels[key] = value
+	}
+
+	rec.Data = p
+	return rec, nil
+}
+
+// walTime returns t in nanoseconds since the epoch, or 0 for the zero time
+func walTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromWALTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+func appendWALString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// readWALString reads a string preceded by its length and returns the rest of p
+func readWALString(p []byte) (string, []byte, bool) {
+	n, k := binary.Uvarint(p)
+	if k <= 0 || uint64(len(p)-k) < n {
+		return "", nil, false
+	}
+	return string(p[k : k+int(n)]), p[k+int(n):], true
+}
+
+// signal sends on a channel with a buffer of 1 without blocking
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
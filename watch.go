@@ -0,0 +1,140 @@
+package tailreader
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// startWatching watches the directories of the file and of all candidate paths for changes
+func (r *TailingReader) startWatching() error {
+	r.setFS(osFS{})
+
+	var err error
+	r.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Dir(r.filePath)
+	err = r.addWatch(path)
+	if err != nil {
+		return err
+	}
+	r.watched = path
+
+	for _, candidate := range r.paths {
+		dir := filepath.Dir(candidate)
+		if dir == path {
+			continue
+		}
+		// adding a directory multiple times has no effect
+		err = r.addWatch(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.updateWatch()
+	return nil
+}
+
+// addWatch starts watching path
+//
+// Paths exceeding MAX_PATH on Windows are watched in their extended-length form,
+// so the names of their events have to be compared using r.eventPath.
+func (r *TailingReader) addWatch(path string) error {
+	if r.options.WatchBufferSize > 0 {
+		return r.watcher.AddWith(longPath(path), fsnotify.WithBufferSize(r.options.WatchBufferSize))
+	}
+	return r.watcher.Add(longPath(path))
+}
+
+// eventPath returns path in the form used to compare event names against the file's path
+//
+// Paths are made absolute and cleaned, so relative paths and paths containing ".."
+// match the names of their events, which are based on the watched directory.
+func (r *TailingReader) eventPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if r.options.ResolveSymlinks {
+		// the file itself may be gone already
+		if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+			path = filepath.Join(dir, filepath.Base(path))
+		}
+	}
+
+	path = normalizeUnicode(longPath(path))
+	if r.options.CaseInsensitive || runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// watchTarget watches the directory of the file's target if the file is a symlink
+//
+// Events for the target are named after the target's path, so without this
+// the deletion of the target of a remaining symlink would go unnoticed.
+func (r *TailingReader) watchTarget() {
+	if r.watcher == nil {
+		return
+	}
+
+	target := ""
+	if resolved, err := filepath.EvalSymlinks(r.filePath); err == nil && r.eventPath(resolved) != r.eventPath(r.filePath) {
+		target = resolved
+	}
+
+	dir := ""
+	if target != "" {
+		dir = filepath.Dir(target)
+	}
+	if dir != r.targetDir {
+		if dir != "" && r.addWatch(dir) != nil {
+			target, dir = "", ""
+		}
+		old := r.targetDir
+		if old != "" && old != dir && old != r.watched && old != filepath.Dir(r.filePath) && !r.isCandidateDir(old) {
+			_ = r.removeWatch(old)
+		}
+		r.targetDir = dir
+	}
+	r.target = target
+}
+
+// removeWatch stops watching path
+func (r *TailingReader) removeWatch(path string) error {
+	return r.watcher.Remove(longPath(path))
+}
+
+// updateWatch watches the file itself while it exists if WatchFile is set, and its directory otherwise
+func (r *TailingReader) updateWatch() {
+	if r.watcher == nil || !r.options.WatchFile {
+		return
+	}
+
+	target := filepath.Dir(r.filePath)
+	if _, err := r.fs.Stat(r.filePath); err == nil {
+		target = r.filePath
+	}
+	if target == r.watched {
+		return
+	}
+
+	err := r.addWatch(target)
+	if err != nil {
+		return
+	}
+	if r.watched != "" && !r.isCandidateDir(r.watched) {
+		// fails if the file's watch was already removed along with the file; directories
+		// of candidate paths stay watched to notice their creation
+		_ = r.removeWatch(r.watched)
+	}
+	r.watched = target
+
+	// changes may have happened before the new watch was in place
+	r.wake()
+}